@@ -0,0 +1,145 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/middleware"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Function for load API key from env API_KEYS and file API_KEYS_FILE.
+// Format of API key is `name:key [scope...]`, separated with comma or new line.
+func NewApiKeys() ([]middleware.ApiKey, error) {
+	// (1) Load API key from env
+	apiKeys, err := ParseApiKeys(os.Getenv("API_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+
+	// (2) Load API key from file
+	if path := os.Getenv("API_KEYS_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		fileApiKeys, err := ParseApiKeys(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		apiKeys = append(apiKeys, fileApiKeys...)
+	}
+
+	// (3) API key from configuration is required, because only this API key can manage other API key
+	if len(apiKeys) == 0 {
+		return nil, errors.New("no API key configured, set API_KEYS or API_KEYS_FILE")
+	}
+
+	return apiKeys, nil
+}
+
+// Function for get how long API key issued at runtime is cached from env API_KEY_CACHE_TTL,
+// e.g. "30s". Revoked API key still can be used until the cache expired.
+func NewApiKeyCacheTTL() (time.Duration, error) {
+	value := os.Getenv("API_KEY_CACHE_TTL")
+	if value == "" {
+		return 30 * time.Second, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// Function for parse API key with format `name:key [scope...]`, separated with comma or new line.
+// Scope is separated with space, API key without scope is granted all scope of category, the same as
+// API key issued at runtime. Scope admin is only granted when listed.
+// Empty line and line started with `#` is ignored.
+func ParseApiKeys(text string) ([]middleware.ApiKey, error) {
+	var apiKeys []middleware.ApiKey
+	names := map[string]bool{}
+
+	entries := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		fields := strings.Fields(entry)
+		name, key, ok := helper.CutString(fields[0], ":")
+		if !ok || name == "" || key == "" {
+			// Not print the entry, because it can contain the key
+			return nil, errors.New("invalid API key, format must be name:key [scope...]")
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate API key name %q", name)
+		}
+		names[name] = true
+
+		apiKey := middleware.ApiKey{Name: name, Key: key}
+		for _, scope := range fields[1:] {
+			if !validScope(scope) {
+				return nil, fmt.Errorf("unknown scope %q for API key %q", scope, name)
+			}
+			apiKey.Scopes = append(apiKey.Scopes, scope)
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, nil
+}
+
+// Function for check whether scope is known
+func validScope(scope string) bool {
+	for _, known := range domain.AllScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Function for create JWT verifier from env, return nil when JWT is not configured.
+// Set JWT_HS256_SECRET for HS256 and JWT_JWKS_FILE for RS256, JWT_ISSUER and JWT_AUDIENCE
+// for check claim iss and aud, and JWT_LEEWAY for allowed clock difference, e.g. "30s".
+func NewJwtVerifier() (*middleware.JwtVerifier, error) {
+	secret := os.Getenv("JWT_HS256_SECRET")
+	jwksFile := os.Getenv("JWT_JWKS_FILE")
+	if secret == "" && jwksFile == "" {
+		return nil, nil
+	}
+
+	verifier := &middleware.JwtVerifier{
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+		Leeway:   30 * time.Second,
+	}
+
+	if secret != "" {
+		verifier.HmacSecret = []byte(secret)
+	}
+
+	if jwksFile != "" {
+		keys, err := middleware.LoadJwks(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.RsaKeys = keys
+	}
+
+	if leeway := os.Getenv("JWT_LEEWAY"); leeway != "" {
+		duration, err := time.ParseDuration(leeway)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_LEEWAY: %w", err)
+		}
+		verifier.Leeway = duration
+	}
+
+	return verifier, nil
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/jabutech/go-crud-restful-api/service"
+)
+
+// Function for load config of category hierarchy from env CATEGORY_MAX_DEPTH and
+// CATEGORY_DELETE_CHILDREN, empty value use default config
+func NewCategoryTreeConfig() (service.CategoryTreeConfig, error) {
+	treeConfig := service.CategoryTreeConfig{
+		DeleteChildren: os.Getenv("CATEGORY_DELETE_CHILDREN"),
+	}
+
+	if value := os.Getenv("CATEGORY_MAX_DEPTH"); value != "" {
+		maxDepth, err := strconv.Atoi(value)
+		if err != nil || maxDepth < 1 {
+			return treeConfig, errors.New("CATEGORY_MAX_DEPTH must be a number greater than 0")
+		}
+		treeConfig.MaxDepth = maxDepth
+	}
+
+	switch treeConfig.DeleteChildren {
+	case "", service.DeleteChildrenReject, service.DeleteChildrenCascade, service.DeleteChildrenReparent:
+		return treeConfig, nil
+	default:
+		return treeConfig, errors.New("CATEGORY_DELETE_CHILDREN must be reject, cascade or reparent")
+	}
+}
+
+// Function for load total category event kept for replay from env CATEGORY_EVENT_BUFFER_SIZE,
+// empty value use service.DefaultEventBufferSize
+func NewCategoryEventBufferSize() (int, error) {
+	value := os.Getenv("CATEGORY_EVENT_BUFFER_SIZE")
+	if value == "" {
+		return service.DefaultEventBufferSize, nil
+	}
+
+	bufferSize, err := strconv.Atoi(value)
+	if err != nil || bufferSize < 1 {
+		return 0, errors.New("CATEGORY_EVENT_BUFFER_SIZE must be a number greater than 0")
+	}
+	return bufferSize, nil
+}
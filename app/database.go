@@ -1,15 +1,34 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"os"
 	"time"
 
 	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/migration"
 	"github.com/joho/godotenv"
 )
 
+// Function for open connection to database, and apply all pending migration
+// when DB_AUTO_MIGRATE=true
 func NewDB() *sql.DB {
+	db := OpenDB()
+
+	if os.Getenv("DB_AUTO_MIGRATE") == "true" {
+		migrator, err := migration.NewMigrator(db)
+		helper.PanicErr(err)
+
+		err = migrator.Up(context.Background())
+		helper.PanicErr(err)
+	}
+
+	return db
+}
+
+// Function for open connection to database without migration
+func OpenDB() *sql.DB {
 	// Load file .env
 	godotenv.Load(".env")
 	// Get variable DATABSE_URL from .env file
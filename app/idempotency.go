@@ -0,0 +1,39 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// Function for get how long key of header Idempotency-Key and its response is saved from env
+// IDEMPOTENCY_KEY_TTL, e.g. "24h". Request with expired key is processed again.
+func NewIdempotencyKeyTTL() (time.Duration, error) {
+	value := os.Getenv("IDEMPOTENCY_KEY_TTL")
+	if value == "" {
+		return 24 * time.Hour, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, errors.New("IDEMPOTENCY_KEY_TTL must be a duration greater than 0, e.g. 24h")
+	}
+
+	return ttl, nil
+}
+
+// Function for get how long key of header Idempotency-Key is reserved for request that still processed
+// from env IDEMPOTENCY_LOCK_TIMEOUT, e.g. "1m". Key without response after it can be used again.
+func NewIdempotencyLockTimeout() (time.Duration, error) {
+	value := os.Getenv("IDEMPOTENCY_LOCK_TIMEOUT")
+	if value == "" {
+		return time.Minute, nil
+	}
+
+	lockTimeout, err := time.ParseDuration(value)
+	if err != nil || lockTimeout <= 0 {
+		return 0, errors.New("IDEMPOTENCY_LOCK_TIMEOUT must be a duration greater than 0, e.g. 1m")
+	}
+
+	return lockTimeout, nil
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jabutech/go-crud-restful-api/migration"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up      apply all pending migration
+  down    rollback the last applied migration
+  status  show status of all migration
+  to N    migrate up or down to version N, 0 rollback all migration
+  force N mark version N as applied and not dirty, after database is fixed manually`
+
+// Function for run migrate subcommand, e.g. `migrate up` or `migrate to 1`
+func RunMigrate(db *sql.DB, args []string, out io.Writer) error {
+	// (1) Check command is available
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// (2) Use migration embedded in binary
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	// (3) Run command
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "to", "force":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			return fmt.Errorf("invalid version %s", args[1])
+		}
+		if args[0] == "force" {
+			err = migrator.Force(ctx, version)
+		} else {
+			err = migrator.To(ctx, version)
+		}
+	case "status":
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	// (4) Print status after run command
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if status.Dirty {
+			appliedAt = "dirty"
+		}
+		fmt.Fprintf(out, "%04d %-30s %s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return nil
+}
@@ -2,16 +2,19 @@ package app
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/jabutech/go-crud-restful-api/controller"
 	"github.com/jabutech/go-crud-restful-api/exception"
 	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/middleware"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
 	"github.com/jabutech/go-crud-restful-api/model/web"
 
 	"github.com/julienschmidt/httprouter"
 )
 
-func NewRouter(categoryController controller.CategoryController) *httprouter.Router {
+func NewRouter(categoryController controller.CategoryController, apiKeyController controller.ApiKeyController) *httprouter.Router {
 	// Use http router
 	router := httprouter.New()
 
@@ -24,21 +27,78 @@ func NewRouter(categoryController controller.CategoryController) *httprouter.Rou
 		}
 
 		// (5) Encode response with helper WriteToResponseBody
-		helper.WriteToResponseBody(w, webResponse)
+		helper.WriteToResponseBody(w, r, webResponse)
 	})
 	// Get all categories
-	router.GET("/api/categories", categoryController.FindAll)
-	// Get category by id
-	router.GET("/api/categories/:categoryId", categoryController.FindById)
+	router.GET("/api/categories", middleware.RequireScope(domain.ScopeCategoriesRead, categoryController.FindAll))
+	// Get category by id, all categories as tree, export all categories as csv or ndjson, or stream category event
+	router.GET("/api/categories/:categoryId", withStaticPath(map[string]httprouter.Handle{
+		"tree":   middleware.RequireScope(domain.ScopeCategoriesRead, categoryController.FindTree),
+		"export": middleware.RequireScope(domain.ScopeCategoriesRead, categoryController.Export),
+		"events": middleware.RequireScope(domain.ScopeCategoriesRead, categoryController.Events),
+	}, middleware.RequireScope(domain.ScopeCategoriesRead, categoryController.FindById)))
+	// Get children and ancestors of category by id
+	router.GET("/api/categories/:categoryId/children", middleware.RequireScope(domain.ScopeCategoriesRead, categoryController.FindChildren))
+	router.GET("/api/categories/:categoryId/ancestors", middleware.RequireScope(domain.ScopeCategoriesRead, categoryController.FindAncestors))
 	// Create new category
-	router.POST("/api/categories", categoryController.Create)
-	// Update category by id
-	router.PUT("/api/categories/:categoryId", categoryController.Update)
-	// Delete category by id
-	router.DELETE("/api/categories/:categoryId", categoryController.Delete)
+	router.POST("/api/categories", middleware.RequireScope(domain.ScopeCategoriesWrite, categoryController.Create))
+	// Create many category in one request, or import categories from csv or ndjson
+	router.POST("/api/categories/:categoryId", withStaticPath(map[string]httprouter.Handle{
+		"bulk":   middleware.RequireScope(domain.ScopeCategoriesWrite, categoryController.BulkCreate),
+		"import": middleware.RequireScope(domain.ScopeCategoriesWrite, categoryController.Import),
+	}, methodNotAllowed(http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)))
+	// Update category by id, or many category in one request
+	router.PUT("/api/categories/:categoryId", withStaticPath(map[string]httprouter.Handle{
+		"bulk": middleware.RequireScope(domain.ScopeCategoriesWrite, categoryController.BulkUpdate),
+	}, middleware.RequireScope(domain.ScopeCategoriesWrite, categoryController.Update)))
+	// Patch category by id with JSON Merge Patch or JSON Patch
+	router.PATCH("/api/categories/:categoryId", middleware.RequireScope(domain.ScopeCategoriesWrite, categoryController.Patch))
+	// Delete category by id, or many category in one request
+	router.DELETE("/api/categories/:categoryId", withStaticPath(map[string]httprouter.Handle{
+		"bulk": middleware.RequireScope(domain.ScopeCategoriesDelete, categoryController.BulkDelete),
+	}, middleware.RequireScope(domain.ScopeCategoriesDelete, categoryController.Delete)))
+	// Restore deleted category by id
+	router.POST("/api/categories/:categoryId/restore", middleware.RequireScope(domain.ScopeCategoriesDelete, categoryController.Restore))
 
-	// Change PanicHandler to exception error hanlder
+	// Manage API key, only for admin
+	router.GET("/api/admin/keys", middleware.RequireScope(domain.ScopeAdmin, apiKeyController.FindAll))
+	router.POST("/api/admin/keys", middleware.RequireScope(domain.ScopeAdmin, apiKeyController.Create))
+	router.POST("/api/admin/keys/:keyId/rotate", middleware.RequireScope(domain.ScopeAdmin, apiKeyController.Rotate))
+	router.DELETE("/api/admin/keys/:keyId", middleware.RequireScope(domain.ScopeAdmin, apiKeyController.Revoke))
+
+	// Permanently delete category that deleted before retention window, only for admin
+	router.POST("/api/admin/categories/purge", middleware.RequireScope(domain.ScopeAdmin, categoryController.Purge))
+
+	// Method that not allowed is written as error response, header Allow is set by router
+	router.MethodNotAllowed = http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		exception.ErrorHandler(writer, request, exception.NewMethodNotAllowedError("method {0} is not allowed", request.Method))
+	})
+	// Controller write error response by itself, PanicHandler only used as the last safety net for unexpected panic
 	router.PanicHandler = exception.ErrorHandler
 
 	return router
 }
+
+// Function for handle static path in the same level as path parameter, e.g. /api/categories/tree,
+// because httprouter not allow static path and path parameter in the same level.
+func withStaticPath(handles map[string]httprouter.Handle, paramHandle httprouter.Handle) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		if len(params) > 0 {
+			if handle, ok := handles[params[len(params)-1].Value]; ok {
+				handle(writer, request, params)
+				return
+			}
+		}
+
+		paramHandle(writer, request, params)
+	}
+}
+
+// Function for handle path parameter that has no handle for the method, e.g. POST /api/categories/1,
+// written as method not allowed error, same as path that not registered for the method
+func methodNotAllowed(allowed ...string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		writer.Header().Set("Allow", strings.Join(allowed, ", "))
+		exception.ErrorHandler(writer, request, exception.NewMethodNotAllowedError("method {0} is not allowed", request.Method))
+	}
+}
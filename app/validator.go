@@ -0,0 +1,24 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator"
+)
+
+// Function for create validator that use name in json tag as field name,
+// so validation error show the same field name with request body
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	return validate
+}
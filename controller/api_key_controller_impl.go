@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/service"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type ApiKeyControllerImpl struct {
+	ApiKeyService service.ApiKeyService // Use api key service
+}
+
+func NewApiKeyController(apiKeyService service.ApiKeyService) ApiKeyController {
+	return &ApiKeyControllerImpl{
+		ApiKeyService: apiKeyService,
+	}
+}
+
+func (controller *ApiKeyControllerImpl) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Decode with helper ReadFromRequestBody
+	apiKeyCreateRequest := web.ApiKeyCreateRequest{}
+	err := readRequestBody(request, &apiKeyCreateRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (2) Issue new API key use service Create
+	apiKeyResponse, err := controller.ApiKeyService.Create(request.Context(), apiKeyCreateRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (3) If success, encode response with helper WriteToResponseBody
+	helper.WriteToResponseBody(writer, request, web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   apiKeyResponse,
+	})
+}
+
+func (controller *ApiKeyControllerImpl) Rotate(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get parameter id and convert to int
+	id, err := parseIdParam("keyId", params.ByName("keyId"))
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (2) Rotate API key use service Rotate
+	apiKeyResponse, err := controller.ApiKeyService.Rotate(request.Context(), id)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (3) If success, encode response with helper WriteToResponseBody
+	helper.WriteToResponseBody(writer, request, web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   apiKeyResponse,
+	})
+}
+
+func (controller *ApiKeyControllerImpl) Revoke(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get parameter id and convert to int
+	id, err := parseIdParam("keyId", params.ByName("keyId"))
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (2) Revoke API key use service Revoke
+	err = controller.ApiKeyService.Revoke(request.Context(), id)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (3) If success, encode response with helper WriteToResponseBody
+	helper.WriteToResponseBody(writer, request, web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+	})
+}
+
+func (controller *ApiKeyControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get all API key use service FindAll
+	apiKeyResponses, err := controller.ApiKeyService.FindAll(request.Context())
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (2) If success, encode response with helper WriteToResponseBody
+	helper.WriteToResponseBody(writer, request, web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   apiKeyResponses,
+	})
+}
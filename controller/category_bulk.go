@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/service"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// Mode of bulk request from query parameter mode
+const (
+	BulkModeAtomic     = "atomic"      // No item is applied when one of item failed, default mode
+	BulkModeBestEffort = "best_effort" // Succeeded item is applied even when other item failed
+)
+
+func (controller *CategoryControllerImpl) BulkCreate(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get mode from query parameter and decode array of item from request body
+	categoryBulkCreateRequest := web.CategoryBulkCreateRequest{}
+	bestEffort, err := readBulkMode(request)
+	if err == nil {
+		err = readRequestBody(request, &categoryBulkCreateRequest.Items)
+	}
+	// (2) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	categoryBulkCreateRequest.BestEffort = bestEffort
+
+	// (3) Create all category use service BulkCreate
+	results, err := controller.CategoryService.BulkCreate(request.Context(), categoryBulkCreateRequest)
+	// (4) Write result of each item, or error response when request is not processed
+	writeBulkResponse(writer, request, results, err)
+}
+
+func (controller *CategoryControllerImpl) BulkUpdate(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get mode from query parameter and decode array of item from request body
+	categoryBulkUpdateRequest := web.CategoryBulkUpdateRequest{}
+	bestEffort, err := readBulkMode(request)
+	if err == nil {
+		err = readRequestBody(request, &categoryBulkUpdateRequest.Items)
+	}
+	// (2) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	categoryBulkUpdateRequest.BestEffort = bestEffort
+
+	// (3) Update all category use service BulkUpdate
+	results, err := controller.CategoryService.BulkUpdate(request.Context(), categoryBulkUpdateRequest)
+	// (4) Write result of each item, or error response when request is not processed
+	writeBulkResponse(writer, request, results, err)
+}
+
+func (controller *CategoryControllerImpl) BulkDelete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get mode from query parameter and decode array of item from request body
+	categoryBulkDeleteRequest := web.CategoryBulkDeleteRequest{}
+	bestEffort, err := readBulkMode(request)
+	if err == nil {
+		err = readRequestBody(request, &categoryBulkDeleteRequest.Items)
+	}
+	// (2) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	categoryBulkDeleteRequest.BestEffort = bestEffort
+
+	// (3) Delete all category use service BulkDelete
+	results, err := controller.CategoryService.BulkDelete(request.Context(), categoryBulkDeleteRequest)
+	// (4) Write result of each item, or error response when request is not processed
+	writeBulkResponse(writer, request, results, err)
+}
+
+// Function for read mode of bulk request from query parameter, return true for best effort mode
+func readBulkMode(request *http.Request) (bool, error) {
+	switch request.URL.Query().Get("mode") {
+	case "", BulkModeAtomic:
+		return false, nil
+	case BulkModeBestEffort:
+		return true, nil
+	default:
+		return false, exception.NewFieldValidationError("mode", "oneof", BulkModeAtomic+" "+BulkModeBestEffort, "{0} must be one of [{1}]", "mode", BulkModeAtomic+" "+BulkModeBestEffort)
+	}
+}
+
+// Function for write result of bulk request with status of each item. Error of item is translated same as error response.
+func writeBulkResponse(writer http.ResponseWriter, request *http.Request, results []service.CategoryBulkResult, err error) {
+	// (1) Request that not processed is written as error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (2) Create result of each item
+	categoryBulkResponse := web.CategoryBulkResponse{Items: []web.CategoryBulkItemResponse{}}
+	for i, result := range results {
+		item := web.CategoryBulkItemResponse{Index: i, Status: http.StatusOK}
+		if result.Err != nil {
+			item.Status, item.Error, item.Errors = exception.TranslateError(request, result.Err)
+			item.ExistingId = exception.ConflictExistingId(result.Err)
+			categoryBulkResponse.Failed++
+		} else {
+			item.Data = result.Category
+			categoryBulkResponse.Succeeded++
+		}
+		categoryBulkResponse.Items = append(categoryBulkResponse.Items, item)
+	}
+
+	// (3) Encode response with helper WriteToResponseBody
+	writeMultiStatusResponse(writer, request, categoryBulkResponse.Failed > 0, categoryBulkResponse)
+}
+
+// Function for write web response with status 207 when one of item failed, otherwise 200
+func writeMultiStatusResponse(writer http.ResponseWriter, request *http.Request, failed bool, data interface{}) {
+	code := http.StatusOK
+	if failed {
+		code = http.StatusMultiStatus
+	}
+	webResponse := web.WebResponse{
+		Code:   code,
+		Status: strings.ToUpper(http.StatusText(code)),
+		Data:   data,
+	}
+
+	helper.WriteResponse(writer, request, code, webResponse)
+}
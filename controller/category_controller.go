@@ -9,7 +9,19 @@ import (
 type CategoryController interface {
 	Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	Update(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Patch(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	FindChildren(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	FindAncestors(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	FindTree(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Restore(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Purge(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	BulkCreate(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	BulkUpdate(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	BulkDelete(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Export(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Import(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Events(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 }
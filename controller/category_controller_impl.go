@@ -3,7 +3,10 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jabutech/go-crud-restful-api/exception"
 	"github.com/jabutech/go-crud-restful-api/helper"
 	"github.com/jabutech/go-crud-restful-api/model/web"
 	"github.com/jabutech/go-crud-restful-api/service"
@@ -25,41 +28,117 @@ func (controller *CategoryControllerImpl) Create(writer http.ResponseWriter, req
 	// (1) Create variable with value web.CategoryCreateRequest
 	categoryCreateRequest := web.CategoryCreateRequest{}
 	// (2) Decode with helper ReadFromRequestBody
-	helper.ReadFromRequestBody(request, &categoryCreateRequest)
+	err := readRequestBody(request, &categoryCreateRequest)
+	// (3) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	// (3) Create new category use service Create
-	categoryResponse := controller.CategoryService.Create(request.Context(), categoryCreateRequest)
+	// (4) Create new category use service Create
+	categoryResponse, err := controller.CategoryService.Create(request.Context(), categoryCreateRequest)
+	// (5) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	// (4) If success, create response with helper web response
+	// (6) If success, create response with helper web response
 	webResponse := web.WebResponse{
 		Code:   http.StatusOK,
 		Status: "OK",
 		Data:   categoryResponse,
 	}
 
-	// (5) Encode response with helper WriteToResponseBody
-	helper.WriteToResponseBody(writer, webResponse)
+	// (7) Encode response with helper WriteToResponseBody
+	writer.Header().Set("ETag", categoryETag(categoryResponse.Version))
+	helper.WriteToResponseBody(writer, request, webResponse)
 
 }
 
 func (controller *CategoryControllerImpl) Update(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	// (1) Create variable with value web.CategoryUpdateRequest{
 	categoryUpdateRequest := web.CategoryUpdateRequest{}
-	// (3) Decode with helper ReadFromRequestBody
-	helper.ReadFromRequestBody(request, &categoryUpdateRequest)
+	// (2) Decode with helper ReadFromRequestBody
+	err := readRequestBody(request, &categoryUpdateRequest)
+	// (3) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
 	// (4) Get parameter id
 	categoryId := params.ByName("categoryId")
-	// (5) Convert to string
-	id, err := strconv.Atoi(categoryId)
-	// (6) If error, handle with helper
-	helper.PanicErr(err)
+	// (5) Convert to int
+	id, err := parseIdParam("categoryId", categoryId)
+	// (6) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	// (7) Parse parameter id to categoryUpdateRequest
+	// (7) Parse parameter id and version from header If-Match to categoryUpdateRequest
 	categoryUpdateRequest.Id = id
+	categoryUpdateRequest.IfMatch, err = readIfMatch(request)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
 	// (8) Update category use service Update
-	categoryResponse := controller.CategoryService.Update(request.Context(), categoryUpdateRequest)
+	categoryResponse, err := controller.CategoryService.Update(request.Context(), categoryUpdateRequest)
+	// (9) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (10) If success, create response with helper web response
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   categoryResponse,
+	}
+
+	// (11) Encode response with helper WriteToResponseBody, ETag is the new version
+	writer.Header().Set("ETag", categoryETag(categoryResponse.Version))
+	helper.WriteToResponseBody(writer, request, webResponse)
+
+}
+
+func (controller *CategoryControllerImpl) Patch(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get parameter id
+	categoryId := params.ByName("categoryId")
+	// (2) Convert to int
+	id, err := parseIdParam("categoryId", categoryId)
+	// (3) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (4) Decode patch with format from content type
+	patch, err := readPatchBody(request)
+	// (5) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (6) Get version from header If-Match
+	ifMatch, err := readIfMatch(request)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (7) Patch category use service Patch
+	categoryResponse, err := controller.CategoryService.Patch(request.Context(), id, patch, ifMatch)
+	// (8) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
 	// (9) If success, create response with helper web response
 	webResponse := web.WebResponse{
@@ -68,68 +147,354 @@ func (controller *CategoryControllerImpl) Update(writer http.ResponseWriter, req
 		Data:   categoryResponse,
 	}
 
-	// (10) Encode response with helper WriteToResponseBody
-	helper.WriteToResponseBody(writer, webResponse)
+	// (10) Encode response with helper WriteToResponseBody, ETag is the new version
+	writer.Header().Set("ETag", categoryETag(categoryResponse.Version))
+	helper.WriteToResponseBody(writer, request, webResponse)
 
 }
 
 func (controller *CategoryControllerImpl) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	// (1) Get parameter id
 	categoryId := params.ByName("categoryId")
-	// (2) Convert to string
-	id, err := strconv.Atoi(categoryId)
-	// (3) If error, handle with helper
-	helper.PanicErr(err)
+	// (2) Convert to int
+	id, err := parseIdParam("categoryId", categoryId)
+	// (3) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (4) Get behavior for children from query parameter and version from header If-Match
+	categoryDeleteRequest := web.CategoryDeleteRequest{
+		Id:       id,
+		Children: request.URL.Query().Get("children"),
+	}
+	categoryDeleteRequest.IfMatch, err = readIfMatch(request)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
 	// (5) Delete category use service Delete
-	controller.CategoryService.Delete(request.Context(), id)
+	err = controller.CategoryService.Delete(request.Context(), categoryDeleteRequest)
+	// (6) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (7) If success, create response with helper web response
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+
+	// (8) Encode response with helper WriteToResponseBody
+	helper.WriteToResponseBody(writer, request, webResponse)
+
+}
+
+func (controller *CategoryControllerImpl) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get parameter id
+	categoryId := params.ByName("categoryId")
+	// (2) Convert to int
+	id, err := parseIdParam("categoryId", categoryId)
+	// (3) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (4) FindById category use service FindById
+	categoryResponse, err := controller.CategoryService.FindById(request.Context(), id)
+	// (5) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (6) Category is not modified when client already has the same version
+	etag := categoryETag(categoryResponse.Version)
+	writer.Header().Set("ETag", etag)
+	if matchIfNoneMatch(request, etag) {
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// (7) If success, create response with helper web response
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   categoryResponse,
+	}
+
+	// (8) Encode response with helper WriteToResponseBody
+	helper.WriteToResponseBody(writer, request, webResponse)
+
+}
+
+func (controller *CategoryControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get pagination from query parameter
+	categoryListRequest, err := readCategoryListRequest(request)
+	// (2) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (3) Get all category use service FindAll
+	categoryPage, err := controller.CategoryService.FindAll(request.Context(), categoryListRequest)
+	// (4) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (5) If success, create response with helper web response
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   categoryPage.Categories,
+		Paging: toPaging(request, categoryPage),
+	}
+
+	// (6) Encode response with helper WriteToResponseBody
+	helper.WriteToResponseBody(writer, request, webResponse)
+
+}
+
+func (controller *CategoryControllerImpl) FindChildren(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get parameter id
+	categoryId := params.ByName("categoryId")
+	// (2) Convert to int
+	id, err := parseIdParam("categoryId", categoryId)
+	// (3) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (4) Get children of category use service FindChildren
+	categoryResponses, err := controller.CategoryService.FindChildren(request.Context(), id)
+	// (5) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
 	// (6) If success, create response with helper web response
 	webResponse := web.WebResponse{
 		Code:   http.StatusOK,
 		Status: "OK",
+		Data:   categoryResponses,
 	}
 
 	// (7) Encode response with helper WriteToResponseBody
-	helper.WriteToResponseBody(writer, webResponse)
+	helper.WriteToResponseBody(writer, request, webResponse)
 
 }
 
-func (controller *CategoryControllerImpl) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+func (controller *CategoryControllerImpl) FindAncestors(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	// (1) Get parameter id
 	categoryId := params.ByName("categoryId")
-	// (2) Convert to string
-	id, err := strconv.Atoi(categoryId)
-	// (3) If error, handle with helper
-	helper.PanicErr(err)
+	// (2) Convert to int
+	id, err := parseIdParam("categoryId", categoryId)
+	// (3) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	// (5) FindById category use service FindById
-	categoryResponse := controller.CategoryService.FindById(request.Context(), id)
+	// (4) Get ancestors of category use service FindAncestors
+	categoryResponses, err := controller.CategoryService.FindAncestors(request.Context(), id)
+	// (5) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
 	// (6) If success, create response with helper web response
 	webResponse := web.WebResponse{
 		Code:   http.StatusOK,
 		Status: "OK",
-		Data:   categoryResponse,
+		Data:   categoryResponses,
 	}
 
 	// (7) Encode response with helper WriteToResponseBody
-	helper.WriteToResponseBody(writer, webResponse)
+	helper.WriteToResponseBody(writer, request, webResponse)
 
 }
 
-func (controller *CategoryControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	// (1) Delete category use service Delete
-	webResponses := controller.CategoryService.FindAll(request.Context())
+func (controller *CategoryControllerImpl) FindTree(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get all category as tree use service FindTree
+	categoryTrees, err := controller.CategoryService.FindTree(request.Context())
+	// (2) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (3) If success, create response with helper web response
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   categoryTrees,
+	}
+
+	// (4) Encode response with helper WriteToResponseBody
+	helper.WriteToResponseBody(writer, request, webResponse)
+
+}
+
+func (controller *CategoryControllerImpl) Restore(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get parameter id
+	categoryId := params.ByName("categoryId")
+	// (2) Convert to int
+	id, err := parseIdParam("categoryId", categoryId)
+	// (3) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (4) Restore category use service Restore
+	categoryResponse, err := controller.CategoryService.Restore(request.Context(), id)
+	// (5) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
 	// (6) If success, create response with helper web response
 	webResponse := web.WebResponse{
 		Code:   http.StatusOK,
 		Status: "OK",
-		Data:   webResponses,
+		Data:   categoryResponse,
 	}
 
 	// (7) Encode response with helper WriteToResponseBody
-	helper.WriteToResponseBody(writer, webResponse)
+	writer.Header().Set("ETag", categoryETag(categoryResponse.Version))
+	helper.WriteToResponseBody(writer, request, webResponse)
+
+}
+
+func (controller *CategoryControllerImpl) Purge(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get retention from query parameter, e.g. 720h
+	categoryPurgeRequest := web.CategoryPurgeRequest{}
+	if value := request.URL.Query().Get("retention"); value != "" {
+		retention, err := time.ParseDuration(value)
+		// (2) If error, write error response
+		if err != nil {
+			exception.ErrorHandler(writer, request, exception.NewFieldValidationError("retention", "duration", "", "{0} must be a duration, e.g. {1}", "retention", "720h"))
+			return
+		}
+		categoryPurgeRequest.Retention = &retention
+	}
+
+	// (3) Purge deleted category use service Purge
+	categoryPurgeResponse, err := controller.CategoryService.Purge(request.Context(), categoryPurgeRequest)
+	// (4) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (5) If success, create response with helper web response
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   categoryPurgeResponse,
+	}
+
+	// (6) Encode response with helper WriteToResponseBody
+	helper.WriteToResponseBody(writer, request, webResponse)
+
+}
+
+// Function for read list request from query parameter, include pagination, search and sort
+func readCategoryListRequest(request *http.Request) (web.CategoryListRequest, error) {
+	query := request.URL.Query()
+	categoryListRequest := web.CategoryListRequest{
+		Q:     query.Get("q"),
+		Match: query.Get("match"),
+		Sort:  query.Get("sort"),
+		Order: query.Get("order"),
+	}
+
+	fields := map[string]*int{
+		"page":   &categoryListRequest.Page,
+		"size":   &categoryListRequest.Size,
+		"after":  &categoryListRequest.After,
+		"before": &categoryListRequest.Before,
+	}
+	for name, field := range fields {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return categoryListRequest, exception.NewFieldValidationError(name, "number", "", "{0} must be a number", name)
+		}
+		*field = number
+	}
+
+	if value := query.Get("updated_since"); value != "" {
+		// Plus sign of time zone that not encoded is decoded as space
+		updatedSince, err := time.Parse(time.RFC3339Nano, strings.ReplaceAll(value, " ", "+"))
+		if err != nil {
+			return categoryListRequest, exception.NewFieldValidationError("updated_since", "datetime", time.RFC3339, "{0} must use format RFC 3339", "updated_since")
+		}
+		categoryListRequest.UpdatedSince = &updatedSince
+	}
+
+	if value := query.Get("include_deleted"); value != "" {
+		includeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return categoryListRequest, exception.NewFieldValidationError("include_deleted", "boolean", "", "{0} must be a boolean", "include_deleted")
+		}
+		categoryListRequest.IncludeDeleted = includeDeleted
+	}
+
+	return categoryListRequest, nil
+}
+
+// Function for create paging info with link to next and previous page
+func toPaging(request *http.Request, categoryPage web.CategoryPageResponse) *web.Paging {
+	paging := &web.Paging{
+		Page:  categoryPage.Page,
+		Size:  categoryPage.Size,
+		Total: categoryPage.Total,
+	}
+
+	// Create link from current url, only change parameter for pagination
+	link := func(name string, value int) string {
+		query := request.URL.Query()
+		query.Del("page")
+		query.Del("after")
+		query.Del("before")
+		query.Set("size", strconv.Itoa(categoryPage.Size))
+		query.Set(name, strconv.Itoa(value))
+
+		return request.URL.Path + "?" + query.Encode()
+	}
+
+	if categoryPage.Page > 0 {
+		if categoryPage.HasNext {
+			paging.Next = link("page", categoryPage.Page+1)
+		}
+		if categoryPage.HasPrev {
+			paging.Prev = link("page", categoryPage.Page-1)
+		}
+	} else {
+		if categoryPage.HasNext {
+			paging.Next = link("after", categoryPage.NextAfter)
+		}
+		if categoryPage.HasPrev {
+			paging.Prev = link("before", categoryPage.PrevBefore)
+		}
+	}
 
+	return paging
 }
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// Interval of comment that keep connection of event stream open, e.g. through proxy with idle timeout
+const eventStreamKeepAliveInterval = 15 * time.Second
+
+func (controller *CategoryControllerImpl) Events(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get id of the last received event from header Last-Event-ID, sent by client when reconnect
+	var lastEventId *int
+	if value := request.Header.Get("Last-Event-ID"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			exception.ErrorHandler(writer, request, exception.NewBadRequestError("header {0} must be a number", "Last-Event-ID"))
+			return
+		}
+		lastEventId = &id
+	}
+
+	// (2) Event is sent as soon as it is published, so response must be flushed
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		exception.ErrorHandler(writer, request, errors.New("response writer does not support flush"))
+		return
+	}
+
+	// (3) Subscribe before header is written, so no event is missed after replay
+	subscription := controller.CategoryService.Subscribe(lastEventId)
+	defer subscription.Close()
+
+	writer.Header().Set("Content-Type", helper.EventStreamMediaType)
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+
+	// (4) Write replayed event, then the next event until client is disconnected
+	for _, event := range subscription.Replay {
+		if err := helper.WriteCategoryEvent(writer, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		var err error
+		select {
+		case <-request.Context().Done():
+			return
+		case event, ok := <-subscription.Events:
+			if !ok {
+				// Subscriber is too slow, client reconnect and resume with header Last-Event-ID
+				log.Printf("%s %s: event stream is closed because client is too slow", request.Method, request.URL.Path)
+				return
+			}
+			err = helper.WriteCategoryEvent(writer, event)
+		case <-keepAlive.C:
+			_, err = io.WriteString(writer, ": keep-alive\n\n")
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
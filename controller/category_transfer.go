@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/service"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func (controller *CategoryControllerImpl) Export(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get format from query parameter, default csv
+	var categoryWriter helper.CategoryWriter
+	format := request.URL.Query().Get("format")
+	switch format {
+	case "", "csv":
+		format = "csv"
+		categoryWriter = helper.NewCategoryCSVWriter(writer)
+	case "ndjson":
+		categoryWriter = helper.NewCategoryNDJSONWriter(writer)
+	default:
+		// (2) If error, write error response
+		exception.ErrorHandler(writer, request, exception.NewFieldValidationError("format", "oneof", "csv ndjson", "{0} must be one of [{1}]", "format", "csv ndjson"))
+		return
+	}
+
+	// (3) Header is only written before the first category, so error before it is still written as error response
+	headerWritten := false
+	writeHeader := func() {
+		if format == "csv" {
+			writer.Header().Set("Content-Type", helper.CSVMediaType+"; charset=utf-8")
+		} else {
+			writer.Header().Set("Content-Type", helper.NDJSONMediaType)
+		}
+		writer.Header().Set("Content-Disposition", `attachment; filename="categories.`+format+`"`)
+		headerWritten = true
+	}
+
+	// (4) Write each category use service Export
+	err := controller.CategoryService.Export(request.Context(), func(category web.CategoryResponse) error {
+		if !headerWritten {
+			writeHeader()
+		}
+		return categoryWriter.Write(category)
+	})
+	if err != nil && !headerWritten {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	if err != nil {
+		// Status is already sent, so client only receive part of file
+		log.Printf("%s %s: %v", request.Method, request.URL.Path, err)
+		return
+	}
+
+	// (5) Write the rest of file, file without category only contain header of csv
+	if !headerWritten {
+		writeHeader()
+	}
+	if err := categoryWriter.Flush(); err != nil {
+		log.Printf("%s %s: %v", request.Method, request.URL.Path, err)
+	}
+}
+
+func (controller *CategoryControllerImpl) Import(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// (1) Get mode and dry run from query parameter
+	categoryImportRequest := web.CategoryImportRequest{}
+	bestEffort, err := readBulkMode(request)
+	if err == nil {
+		categoryImportRequest.DryRun, err = readDryRun(request)
+	}
+	// (2) Read row from file
+	if err == nil {
+		categoryImportRequest.Rows, err = helper.ReadCategoryImport(request, service.MaxImportRows)
+		err = requestBodyError(err)
+	}
+	// (3) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	categoryImportRequest.BestEffort = bestEffort
+
+	// (4) Import category use service Import
+	results, err := controller.CategoryService.Import(request.Context(), categoryImportRequest)
+	// (5) If error, write error response
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (6) Count result of each row, only failed row is written with its error
+	categoryImportResponse := web.CategoryImportResponse{
+		DryRun: categoryImportRequest.DryRun,
+		Errors: []web.CategoryImportRowResponse{},
+	}
+	var failedDependencyError exception.FailedDependencyError
+	for i, result := range results {
+		row := categoryImportRequest.Rows[i]
+		switch {
+		case errors.As(result.Err, &failedDependencyError):
+			// Row is valid, but not written because other row failed
+		case result.Err != nil:
+			rowResponse := web.CategoryImportRowResponse{Line: row.Line}
+			rowResponse.Status, rowResponse.Error, rowResponse.Errors = exception.TranslateError(request, result.Err)
+			rowResponse.ExistingId = exception.ConflictExistingId(result.Err)
+			categoryImportResponse.Errors = append(categoryImportResponse.Errors, rowResponse)
+			categoryImportResponse.Failed++
+		case row.Id == 0:
+			categoryImportResponse.Created++
+		default:
+			categoryImportResponse.Updated++
+		}
+	}
+	categoryImportResponse.Applied = !categoryImportResponse.DryRun && (bestEffort || categoryImportResponse.Failed == 0)
+
+	// (7) Encode response with helper WriteToResponseBody
+	writeMultiStatusResponse(writer, request, categoryImportResponse.Failed > 0, categoryImportResponse)
+}
+
+// Function for read dry run from query parameter
+func readDryRun(request *http.Request) (bool, error) {
+	value := request.URL.Query().Get("dry_run")
+	if value == "" {
+		return false, nil
+	}
+
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, exception.NewFieldValidationError("dry_run", "boolean", "", "{0} must be a boolean", "dry_run")
+	}
+
+	return dryRun, nil
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+)
+
+// Function for create entity tag of category from version, e.g. "3"
+func categoryETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Function for split entity tags in header If-Match or If-None-Match, `*` is returned as it is
+func splitETags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// Function for read version from header If-Match with strong comparison, so weak tag never match.
+// Return empty when header is not set or `*`, and precondition failed error when no tag can match.
+func readIfMatch(request *http.Request) ([]int, error) {
+	value := request.Header.Get("If-Match")
+	if value == "" {
+		return nil, nil
+	}
+
+	var versions []int
+	for _, tag := range splitETags(value) {
+		if tag == "*" {
+			return nil, nil
+		}
+		if strings.HasPrefix(tag, "W/") || len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, exception.NewPreconditionFailedError("category has been modified, get the latest version and try again")
+	}
+
+	return versions, nil
+}
+
+// Function for check whether header If-None-Match match the entity tag with weak comparison
+func matchIfNoneMatch(request *http.Request, etag string) bool {
+	for _, tag := range splitETags(request.Header.Get("If-None-Match")) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
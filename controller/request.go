@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+)
+
+// Function for decode request body, error of request body is returned as bad request error
+func readRequestBody(request *http.Request, result interface{}) error {
+	return requestBodyError(helper.ReadFromRequestBody(request, result))
+}
+
+// Function for decode patch from request body, error of request body is returned as bad request error
+func readPatchBody(request *http.Request) (helper.Patch, error) {
+	patch, err := helper.ReadPatchFromRequestBody(request)
+	return patch, requestBodyError(err)
+}
+
+// Function for convert error of request body to bad request or unsupported media type error
+func requestBodyError(err error) error {
+	var requestBodyError helper.RequestBodyError
+	if errors.As(err, &requestBodyError) {
+		if requestBodyError.UnsupportedMediaType {
+			return exception.NewUnsupportedMediaTypeError(requestBodyError.Message.Key, requestBodyError.Message.Args...)
+		}
+		return exception.NewBadRequestError(requestBodyError.Message.Key, requestBodyError.Message.Args...)
+	}
+
+	return err
+}
+
+// Function for convert id from path parameter, id that not a number is returned as bad request error
+func parseIdParam(name string, value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, exception.NewBadRequestError("{0} must be a number", name)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,45 @@
+package exception
+
+import (
+	"net/http"
+
+	"github.com/jabutech/go-crud-restful-api/helper"
+)
+
+type BadRequestError struct {
+	Message helper.Message
+	Code    int // Status code of response, 400, 405, 406, 413, 415 or 422
+}
+
+func NewBadRequestError(key string, args ...string) BadRequestError {
+	return BadRequestError{Message: helper.NewMessage(key, args...), Code: http.StatusBadRequest}
+}
+
+// Function for create error of request body with content type that not supported
+func NewUnsupportedMediaTypeError(key string, args ...string) BadRequestError {
+	return BadRequestError{Message: helper.NewMessage(key, args...), Code: http.StatusUnsupportedMediaType}
+}
+
+// Function for create error of request with method that not allowed for the path
+func NewMethodNotAllowedError(key string, args ...string) BadRequestError {
+	return BadRequestError{Message: helper.NewMessage(key, args...), Code: http.StatusMethodNotAllowed}
+}
+
+// Function for create error of request with header Accept that no media type is supported
+func NewNotAcceptableError(key string, args ...string) BadRequestError {
+	return BadRequestError{Message: helper.NewMessage(key, args...), Code: http.StatusNotAcceptable}
+}
+
+// Function for create error of request body that too large
+func NewRequestEntityTooLargeError(key string, args ...string) BadRequestError {
+	return BadRequestError{Message: helper.NewMessage(key, args...), Code: http.StatusRequestEntityTooLarge}
+}
+
+// Function for create error of request that valid, but can not be processed, e.g. key that already used for other request
+func NewUnprocessableEntityError(key string, args ...string) BadRequestError {
+	return BadRequestError{Message: helper.NewMessage(key, args...), Code: http.StatusUnprocessableEntity}
+}
+
+func (exception BadRequestError) Error() string {
+	return exception.Message.String()
+}
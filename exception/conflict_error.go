@@ -0,0 +1,31 @@
+package exception
+
+import "github.com/jabutech/go-crud-restful-api/helper"
+
+type ConflictError struct {
+	Message    helper.Message
+	ExistingId int // Id of existing data that conflict with request, 0 when not available
+}
+
+func NewConflictError(key string, args ...string) ConflictError {
+	return ConflictError{Message: helper.NewMessage(key, args...)}
+}
+
+// Function for create conflict error because data with same unique value already exists
+func NewDuplicateError(existingId int, key string, args ...string) ConflictError {
+	return ConflictError{Message: helper.NewMessage(key, args...), ExistingId: existingId}
+}
+
+func (exception ConflictError) Error() string {
+	return exception.Message.String()
+}
+
+// Function for get id of existing data from conflict error, 0 when err is not conflict error or id is not available
+func ConflictExistingId(err interface{}) int {
+	var conflictError ConflictError
+	if errorAs(err, &conflictError) {
+		return conflictError.ExistingId
+	}
+
+	return 0
+}
@@ -1,7 +1,11 @@
 package exception
 
 import (
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
 
 	"github.com/jabutech/go-crud-restful-api/helper"
 	"github.com/jabutech/go-crud-restful-api/model/web"
@@ -9,68 +13,159 @@ import (
 	"github.com/go-playground/validator"
 )
 
+// Media type of error response with format RFC 7807
+const ProblemMediaType = helper.ProblemMediaType
+
+// Function for write error response, used by controller for error returned by service
+// and by router as panic handler
 func ErrorHandler(writer http.ResponseWriter, request *http.Request, err interface{}) {
+	code, detail, fieldErrors := TranslateError(request, err)
+	writeError(writer, request, code, detail, fieldErrors, ConflictExistingId(err))
+}
 
-	if notFoundError(writer, request, err) {
-		return
+// Function for get status code, message and error of each field from error, message is translated
+// to language in header Accept-Language. Used for error response and result of item in bulk request.
+func TranslateError(request *http.Request, err interface{}) (int, string, []web.FieldError) {
+	code, message, fieldErrors := resolveError(request, err)
+
+	// Translate message from its key, detail of validation error is all message of field
+	trans := requestTranslator(request)
+	var messages []string
+	translatedErrors := make([]web.FieldError, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		translatedErrors[i] = web.FieldError{
+			Field:   fieldError.Field,
+			Rule:    fieldError.Rule,
+			Param:   fieldError.Param,
+			Message: translate(trans, fieldError.Message),
+		}
+		messages = append(messages, translatedErrors[i].Message)
 	}
 
-	if validationErrors(writer, request, err) {
-		return
+	detail := strings.Join(messages, ", ")
+	if message.Key != "" {
+		detail = translate(trans, message)
 	}
 
-	internalServerError(writer, request, err)
+	return code, detail, translatedErrors
 }
 
-func validationErrors(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
-	exception, ok := err.(validator.ValidationErrors)
-	if ok {
-		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusBadRequest)
+// Function for get status code, message and error of each field from type of error
+func resolveError(request *http.Request, err interface{}) (int, helper.Message, []FieldError) {
+	var notFoundError NotFoundError
+	var validationError ValidationError
+	var badRequestError BadRequestError
+	var conflictError ConflictError
+	var preconditionFailedError PreconditionFailedError
+	var failedDependencyError FailedDependencyError
+	var unauthorizedError UnauthorizedError
+	var forbiddenError ForbiddenError
+	var requestBodyError helper.RequestBodyError
+
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		validationError = NewValidationError(validationErrors)
+		err = validationError
+	}
 
-		webResponse := web.WebResponse{
-			Code:   http.StatusBadRequest,
-			Status: "BAD REQUEST",
-			Data:   exception.Error(),
+	switch {
+	case errorAs(err, &notFoundError):
+		return http.StatusNotFound, notFoundError.Message, nil
+	case errorAs(err, &validationError):
+		// Message is created from error of each field after translated
+		if len(validationError.Errors) > 0 {
+			return http.StatusBadRequest, helper.Message{}, validationError.Errors
+		}
+		return http.StatusBadRequest, validationError.Message, nil
+	case errorAs(err, &badRequestError):
+		return badRequestError.Code, badRequestError.Message, nil
+	case errorAs(err, &conflictError):
+		return http.StatusConflict, conflictError.Message, nil
+	case errorAs(err, &preconditionFailedError):
+		return http.StatusPreconditionFailed, preconditionFailedError.Message, nil
+	case errorAs(err, &failedDependencyError):
+		return http.StatusFailedDependency, failedDependencyError.Message, nil
+	case errorAs(err, &unauthorizedError):
+		return http.StatusUnauthorized, unauthorizedError.Message, nil
+	case errorAs(err, &forbiddenError):
+		return http.StatusForbidden, forbiddenError.Message, nil
+	case errorAs(err, &requestBodyError):
+		// Error of request body that returned by service, e.g. row of imported file that can not be read
+		if requestBodyError.UnsupportedMediaType {
+			return http.StatusUnsupportedMediaType, requestBodyError.Message, nil
 		}
+		return http.StatusBadRequest, requestBodyError.Message, nil
+	default:
+		// Log detail of error, client only receive a fixed message, because detail of error
+		// may contain internal information, e.g. error of database driver or panic
+		log.Printf("%s %s: %v", request.Method, request.URL.Path, err)
 
-		helper.WriteToResponseBody(writer, webResponse)
+		return http.StatusInternalServerError, helper.NewMessage("internal server error"), nil
+	}
+}
 
-		return true
-	} else {
+// Function for check whether err is error with type of target
+func errorAs(err interface{}, target interface{}) bool {
+	e, ok := err.(error)
+	if !ok {
 		return false
 	}
-}
 
-func notFoundError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
-	exception, ok := err.(NotFoundError)
-	if ok {
-		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusNotFound)
+	return errors.As(e, target)
+}
 
-		webResponse := web.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: "NOT FOUND",
-			Data:   exception.Error,
+// Function for write error response as problem+json when requested in header Accept,
+// otherwise as web response with error of each field as data when available.
+// Message must be already translated to language in header Accept-Language.
+// Id of existing data is written when conflict with existing data, 0 is not written.
+func writeError(writer http.ResponseWriter, request *http.Request, code int, detail string, fieldErrors []web.FieldError, existingId int) {
+	// (1) Set language of message
+	trans := requestTranslator(request)
+	writer.Header().Set("Content-Language", trans.Locale())
+
+	// (2) Write problem when requested
+	if acceptProblem(request) {
+		writer.Header().Set("Content-Type", ProblemMediaType)
+		writer.Header().Add("Vary", "Accept")
+		writer.WriteHeader(code)
+
+		problem := web.Problem{
+			Type:     "about:blank",
+			Title:    translate(trans, helper.NewMessage(http.StatusText(code))),
+			Status:   code,
+			Detail:   detail,
+			Instance: request.URL.RequestURI(),
+			Errors:   fieldErrors,
+			// Extension member of problem
+			ExistingId: existingId,
 		}
 
-		helper.WriteToResponseBody(writer, webResponse)
+		helper.PanicErr(json.NewEncoder(writer).Encode(problem))
+		return
+	}
 
-		return true
-	} else {
-		return false
+	// (3) Write web response with media type from header Accept
+	webResponse := web.WebResponse{
+		Code:   code,
+		Status: strings.ToUpper(http.StatusText(code)),
+	}
+	if len(fieldErrors) > 0 {
+		webResponse.Data = fieldErrors
+	} else if existingId > 0 {
+		webResponse.Data = web.ConflictResponse{Message: detail, ExistingId: existingId}
+	} else if detail != "" {
+		webResponse.Data = detail
 	}
-}
 
-func internalServerError(writer http.ResponseWriter, request *http.Request, err interface{}) {
-	writer.Header().Set("Content-Type", "application/json")
-	writer.WriteHeader(http.StatusInternalServerError)
+	helper.WriteResponse(writer, request, code, webResponse)
+}
 
-	webResponse := web.WebResponse{
-		Code:   http.StatusInternalServerError,
-		Status: "INTERNAL SERVER ERROR",
-		Data:   err,
+// Function for check whether header Accept contain media type of problem with quality more than 0
+func acceptProblem(request *http.Request) bool {
+	for _, mediaRange := range helper.ParseAccept(request.Header.Get("Accept")) {
+		if mediaRange.Type == ProblemMediaType {
+			return mediaRange.Quality > 0
+		}
 	}
 
-	helper.WriteToResponseBody(writer, webResponse)
+	return false
 }
@@ -0,0 +1,17 @@
+package exception
+
+import "github.com/jabutech/go-crud-restful-api/helper"
+
+// Error when request is not applied because other request it depends on failed,
+// e.g. item of atomic bulk request
+type FailedDependencyError struct {
+	Message helper.Message
+}
+
+func NewFailedDependencyError(key string, args ...string) FailedDependencyError {
+	return FailedDependencyError{Message: helper.NewMessage(key, args...)}
+}
+
+func (exception FailedDependencyError) Error() string {
+	return exception.Message.String()
+}
@@ -0,0 +1,15 @@
+package exception
+
+import "github.com/jabutech/go-crud-restful-api/helper"
+
+type ForbiddenError struct {
+	Message helper.Message
+}
+
+func NewForbiddenError(key string, args ...string) ForbiddenError {
+	return ForbiddenError{Message: helper.NewMessage(key, args...)}
+}
+
+func (exception ForbiddenError) Error() string {
+	return exception.Message.String()
+}
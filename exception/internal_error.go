@@ -0,0 +1,21 @@
+package exception
+
+type InternalError struct {
+	Message string
+	Err     error
+}
+
+func NewInternalError(err error) InternalError {
+	return InternalError{
+		Message: err.Error(),
+		Err:     err,
+	}
+}
+
+func (exception InternalError) Error() string {
+	return exception.Message
+}
+
+func (exception InternalError) Unwrap() error {
+	return exception.Err
+}
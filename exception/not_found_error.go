@@ -1,9 +1,15 @@
 package exception
 
+import "github.com/jabutech/go-crud-restful-api/helper"
+
 type NotFoundError struct {
-	Error string
+	Message helper.Message
+}
+
+func NewNotFoundError(key string, args ...string) NotFoundError {
+	return NotFoundError{Message: helper.NewMessage(key, args...)}
 }
 
-func NewNotFoundError(error string) NotFoundError {
-	return NotFoundError{Error: error}
+func (exception NotFoundError) Error() string {
+	return exception.Message.String()
 }
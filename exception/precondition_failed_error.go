@@ -0,0 +1,16 @@
+package exception
+
+import "github.com/jabutech/go-crud-restful-api/helper"
+
+// Error when precondition of request, e.g. header If-Match, is not match with current data
+type PreconditionFailedError struct {
+	Message helper.Message
+}
+
+func NewPreconditionFailedError(key string, args ...string) PreconditionFailedError {
+	return PreconditionFailedError{Message: helper.NewMessage(key, args...)}
+}
+
+func (exception PreconditionFailedError) Error() string {
+	return exception.Message.String()
+}
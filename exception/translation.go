@@ -0,0 +1,117 @@
+package exception
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jabutech/go-crud-restful-api/helper"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+)
+
+// Catalog of message for each language, key is key of helper.Message, the message in english
+// with `{0}`, `{1}` for the changing part of message, e.g. name of field
+//
+//go:embed locales/*.json
+var catalogs embed.FS
+
+// Language used when no language in header Accept-Language is supported
+const FallbackLanguage = "en"
+
+var translator = newTranslator()
+
+// Function for create translator with all language that has catalog
+func newTranslator() *ut.UniversalTranslator {
+	universalTranslator := ut.New(en.New(), en.New(), id.New())
+
+	for _, language := range []locales.Translator{en.New(), id.New()} {
+		trans, _ := universalTranslator.GetTranslator(language.Locale())
+		for key, text := range readCatalog(language.Locale()) {
+			if err := trans.Add(key, text, false); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	return universalTranslator
+}
+
+// Function for read catalog of language from embedded file
+func readCatalog(language string) map[string]string {
+	content, err := catalogs.ReadFile(path.Join("locales", language+".json"))
+	if err != nil {
+		panic(err)
+	}
+
+	catalog := map[string]string{}
+	if err := json.Unmarshal(content, &catalog); err != nil {
+		panic(err)
+	}
+
+	return catalog
+}
+
+// Function for find translator for language in header Accept-Language, ordered by quality.
+// Return translator of fallback language when no language is supported.
+func requestTranslator(request *http.Request) ut.Translator {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []language
+	for _, value := range strings.Split(request.Header.Get("Accept-Language"), ",") {
+		params := strings.Split(value, ";")
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && strings.EqualFold(param[:2], "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			languages = append(languages, language{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	// Use region language or the base language, e.g. "id-ID" or "id"
+	var tags []string
+	for _, language := range languages {
+		tag := strings.ReplaceAll(language.tag, "-", "_")
+		tags = append(tags, tag)
+		if base, _, ok := helper.CutString(tag, "_"); ok {
+			tags = append(tags, base)
+		}
+	}
+
+	trans, _ := translator.FindTranslator(tags...)
+	return trans
+}
+
+// Function for translate message from its key, message that not in catalog is returned in english
+func translate(trans ut.Translator, message helper.Message) string {
+	if text, err := trans.T(message.Key, message.Args...); err == nil {
+		return text
+	}
+
+	return message.String()
+}
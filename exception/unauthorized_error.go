@@ -0,0 +1,15 @@
+package exception
+
+import "github.com/jabutech/go-crud-restful-api/helper"
+
+type UnauthorizedError struct {
+	Message helper.Message
+}
+
+func NewUnauthorizedError(key string, args ...string) UnauthorizedError {
+	return UnauthorizedError{Message: helper.NewMessage(key, args...)}
+}
+
+func (exception UnauthorizedError) Error() string {
+	return exception.Message.String()
+}
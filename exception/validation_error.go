@@ -0,0 +1,64 @@
+package exception
+
+import (
+	"strings"
+
+	"github.com/jabutech/go-crud-restful-api/helper"
+
+	"github.com/go-playground/validator"
+)
+
+type ValidationError struct {
+	Message helper.Message // Message when error is not for field
+	Errors  []FieldError
+}
+
+// Error of one field, message is translated when written to response
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message helper.Message
+}
+
+func NewValidationError(err error) ValidationError {
+	// Convert detail of validator errors when available
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return ValidationError{Message: helper.NewMessage(err.Error())}
+	}
+
+	var fieldErrors []FieldError
+	for _, fieldError := range validationErrors {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fieldError.Field(),
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: validationMessage(fieldError),
+		})
+	}
+
+	return ValidationError{Errors: fieldErrors}
+}
+
+// Function for create validation error of one field, for rule that not checked by validator
+func NewFieldValidationError(field string, rule string, param string, key string, args ...string) ValidationError {
+	return ValidationError{
+		Errors: []FieldError{
+			{Field: field, Rule: rule, Param: param, Message: helper.NewMessage(key, args...)},
+		},
+	}
+}
+
+// Function Error return message of each field separated by comma
+func (exception ValidationError) Error() string {
+	if len(exception.Errors) == 0 {
+		return exception.Message.String()
+	}
+
+	messages := make([]string, len(exception.Errors))
+	for i, fieldError := range exception.Errors {
+		messages[i] = fieldError.Message.String()
+	}
+	return strings.Join(messages, ", ")
+}
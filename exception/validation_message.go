@@ -0,0 +1,36 @@
+package exception
+
+import (
+	"reflect"
+
+	"github.com/jabutech/go-crud-restful-api/helper"
+
+	"github.com/go-playground/validator"
+)
+
+// Function for create message of validator error that can be shown to user
+func validationMessage(fieldError validator.FieldError) helper.Message {
+	field, param := fieldError.Field(), fieldError.Param()
+
+	// Rule min and max check length for string and slice, and value for number
+	min, max := "{0} must be {1} or greater", "{0} must be {1} or less"
+	switch fieldError.Kind() {
+	case reflect.String:
+		min, max = "{0} must be at least {1} characters", "{0} must be at most {1} characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		min, max = "{0} must be at least {1} items", "{0} must be at most {1} items"
+	}
+
+	switch fieldError.Tag() {
+	case "required":
+		return helper.NewMessage("{0} is required", field)
+	case "min":
+		return helper.NewMessage(min, field, param)
+	case "max":
+		return helper.NewMessage(max, field, param)
+	case "oneof":
+		return helper.NewMessage("{0} must be one of [{1}]", field, param)
+	default:
+		return helper.NewMessage("{0} is not valid", field)
+	}
+}
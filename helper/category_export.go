@@ -0,0 +1,163 @@
+package helper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/model/web"
+)
+
+// Media type of file for export and import category
+const (
+	CSVMediaType    = "text/csv"
+	NDJSONMediaType = "application/x-ndjson" // One JSON object for each line
+)
+
+// Column of category in csv file, in order
+var CategoryCSVColumns = []string{"id", "name", "parent_id", "version", "created_at", "updated_at", "created_by", "updated_by"}
+
+// Character at the start of cell that make spreadsheet read the cell as formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// Writer for write category one by one to file, data is only complete after Flush
+type CategoryWriter interface {
+	Write(category web.CategoryResponse) error
+	Flush() error
+}
+
+// Writer for csv, header is written before the first category
+type categoryCSVWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+// Writer for ndjson, each category is written in one line
+type categoryNDJSONWriter struct {
+	encoder *json.Encoder
+}
+
+func NewCategoryCSVWriter(writer io.Writer) CategoryWriter {
+	return &categoryCSVWriter{writer: csv.NewWriter(writer)}
+}
+
+func NewCategoryNDJSONWriter(writer io.Writer) CategoryWriter {
+	return &categoryNDJSONWriter{encoder: json.NewEncoder(writer)}
+}
+
+// Function Write with follow the contract category writer
+func (writer *categoryCSVWriter) Write(category web.CategoryResponse) error {
+	if err := writer.writeHeader(); err != nil {
+		return err
+	}
+
+	return writer.writer.Write(CategoryCSVRecord(category))
+}
+
+// Function Flush with follow the contract category writer, file without category only contain header
+func (writer *categoryCSVWriter) Flush() error {
+	if err := writer.writeHeader(); err != nil {
+		return err
+	}
+
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+func (writer *categoryCSVWriter) writeHeader() error {
+	if writer.headerWritten {
+		return nil
+	}
+
+	writer.headerWritten = true
+	return writer.writer.Write(CategoryCSVColumns)
+}
+
+// Function Write with follow the contract category writer
+func (writer *categoryNDJSONWriter) Write(category web.CategoryResponse) error {
+	return writer.encoder.Encode(category)
+}
+
+// Function Flush with follow the contract category writer, encoder write each line directly
+func (writer *categoryNDJSONWriter) Flush() error {
+	return nil
+}
+
+// Function for convert category to csv record with the same order as CategoryCSVColumns
+func CategoryCSVRecord(category web.CategoryResponse) []string {
+	parentId := ""
+	if category.ParentId != nil {
+		parentId = strconv.Itoa(*category.ParentId)
+	}
+
+	return []string{
+		strconv.Itoa(category.Id),
+		escapeCSVCell(category.Name),
+		parentId,
+		strconv.Itoa(category.Version),
+		category.CreatedAt.Format(time.RFC3339Nano),
+		category.UpdatedAt.Format(time.RFC3339Nano),
+		escapeCSVCell(category.CreatedBy),
+		escapeCSVCell(category.UpdatedBy),
+	}
+}
+
+// Function for check whether cell is read as formula by spreadsheet, ' at the start is skipped
+// so cell that already escaped is also escaped again
+func isCSVFormula(value string) bool {
+	value = strings.TrimLeft(value, "'")
+	return value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0]))
+}
+
+// Function for escape cell that read as formula with prefix ', so file can be opened safely in spreadsheet
+func escapeCSVCell(value string) string {
+	if isCSVFormula(value) {
+		return "'" + value
+	}
+	return value
+}
+
+// Function for remove ' that added by escapeCSVCell, so exported file can be imported again
+func unescapeCSVCell(value string) string {
+	if strings.HasPrefix(value, "'") && isCSVFormula(value) {
+		return value[1:]
+	}
+	return value
+}
+
+// Encoder for web response with category as data, e.g. list of category as csv.
+// Other response can not be encoded, so it is encoded as JSON.
+type categoryListEncoder struct {
+	newWriter func(writer io.Writer) CategoryWriter
+}
+
+// Function Encode with follow the contract encoder
+func (encoder categoryListEncoder) Encode(writer io.Writer, response interface{}) error {
+	// (1) Get category from data of web response
+	webResponse, ok := response.(web.WebResponse)
+	if !ok {
+		return ErrNotEncodable
+	}
+	var categories []web.CategoryResponse
+	switch data := webResponse.Data.(type) {
+	case web.CategoryResponse:
+		categories = []web.CategoryResponse{data}
+	case []web.CategoryResponse:
+		categories = data
+	default:
+		return ErrNotEncodable
+	}
+
+	// (2) Write each category
+	categoryWriter := encoder.newWriter(writer)
+	for _, category := range categories {
+		if err := categoryWriter.Write(category); err != nil {
+			return err
+		}
+	}
+
+	return categoryWriter.Flush()
+}
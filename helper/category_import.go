@@ -0,0 +1,218 @@
+package helper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/jabutech/go-crud-restful-api/model/web"
+)
+
+// Media type of multipart form for upload file
+const MultipartMediaType = "multipart/form-data"
+
+// Function for read category from imported csv or ndjson file, format is chosen by content type.
+// File is request body, or field file of multipart form. Row that can not be read is returned with Error,
+// so other row can still be checked. File with more than maxRows row is rejected.
+func ReadCategoryImport(request *http.Request, maxRows int) ([]web.CategoryImportRow, error) {
+	// (1) Check content type of request body
+	contentType := request.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil, RequestBodyError{
+			Message:              NewMessage("content type is required, use {0}", CSVMediaType+", "+NDJSONMediaType+", "+MultipartMediaType),
+			UnsupportedMediaType: true,
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	// (2) File of multipart form is in field file
+	var file io.Reader = request.Body
+	multipart := mediaType == MultipartMediaType
+	if multipart {
+		var err error
+		file, contentType, err = multipartFile(request)
+		if err != nil {
+			return nil, err
+		}
+		mediaType, _, _ = mime.ParseMediaType(contentType)
+	}
+
+	// (3) Read row with format of file
+	switch mediaType {
+	case CSVMediaType:
+		return readCategoryCSV(file, maxRows)
+	case NDJSONMediaType:
+		return readCategoryNDJSON(file, maxRows)
+	}
+
+	message := NewMessage("content type {0} is not supported, use {1}", contentType, CSVMediaType+", "+NDJSONMediaType+", "+MultipartMediaType)
+	if multipart {
+		message = NewMessage("content type {0} is not supported, use {1}", contentType, CSVMediaType+", "+NDJSONMediaType)
+	}
+	return nil, RequestBodyError{Message: message, UnsupportedMediaType: true}
+}
+
+// Function for find field file in multipart form, return the file and its content type.
+// File without content type use content type from its extension.
+func multipartFile(request *http.Request) (io.Reader, string, error) {
+	reader, err := request.MultipartReader()
+	if err != nil {
+		return nil, "", RequestBodyError{Message: NewMessage("multipart form is not valid")}
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, "", RequestBodyError{Message: NewMessage("field file is required in multipart form")}
+		}
+		if err != nil {
+			return nil, "", RequestBodyError{Message: NewMessage("multipart form is not valid")}
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		contentType := part.Header.Get("Content-Type")
+		if contentType == "" || contentType == "application/octet-stream" {
+			switch strings.ToLower(path.Ext(part.FileName())) {
+			case ".csv":
+				contentType = CSVMediaType
+			case ".ndjson", ".jsonl":
+				contentType = NDJSONMediaType
+			}
+		}
+		return part, contentType, nil
+	}
+}
+
+// Function for read row from csv file. The first line is header with name of column, column id and
+// parent_id is optional and other column is ignored, so exported file can be imported again.
+func readCategoryCSV(file io.Reader, maxRows int) ([]web.CategoryImportRow, error) {
+	reader := csv.NewReader(file)
+	// Row with different total column is still read, missing column is empty
+	reader.FieldsPerRecord = -1
+
+	// (1) Read header, header from spreadsheet may start with byte order mark
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, RequestBodyError{Message: NewMessage("csv file must contain header")}
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, RequestBodyError{Message: NewMessage("column name is required in header of csv file")}
+	}
+
+	// (2) Read each row, one row at a time
+	var rows []web.CategoryImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		if len(rows) == maxRows {
+			return nil, RequestBodyError{Message: NewMessage("file must contain at most {0} rows", strconv.Itoa(maxRows))}
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := web.CategoryImportRow{Line: line, Name: unescapeCSVCell(value("name"))}
+		row.Id, row.Error = importNumber("id", value("id"))
+		if row.Error == nil {
+			row.ParentId, row.Error = importNumber("parent_id", value("parent_id"))
+		}
+		rows = append(rows, row)
+	}
+}
+
+// Function for convert value of number column, empty value is 0
+func importNumber(column string, value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, RequestBodyError{Message: NewMessage("{0} must be a number", column)}
+	}
+
+	return number, nil
+}
+
+// Function for create message of error from csv reader
+func csvError(err error) error {
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) {
+		return RequestBodyError{Message: NewMessage("csv file is not valid at line {0}", strconv.Itoa(parseError.Line))}
+	}
+
+	return err
+}
+
+// Function for read row from ndjson file, each line is one JSON object and empty line is skipped.
+// Unknown field is ignored, so exported file can be imported again.
+func readCategoryNDJSON(file io.Reader, maxRows int) ([]web.CategoryImportRow, error) {
+	scanner := bufio.NewScanner(file)
+	var rows []web.CategoryImportRow
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if len(rows) == maxRows {
+			return nil, RequestBodyError{Message: NewMessage("file must contain at most {0} rows", strconv.Itoa(maxRows))}
+		}
+
+		row := web.CategoryImportRow{}
+		if err := json.Unmarshal(text, &row); err != nil {
+			row = web.CategoryImportRow{Error: RequestBodyError{Message: rowErrorMessage(err)}}
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return nil, RequestBodyError{Message: NewMessage("line {0} of file is too long", strconv.Itoa(line+1))}
+	}
+	return rows, scanner.Err()
+}
+
+// Function for create message of error from json decoder for one row
+func rowErrorMessage(err error) Message {
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return NewMessage("field {0} in row must be {1}", typeError.Field, jsonTypeName(typeError.Type.Kind().String()))
+	}
+
+	return NewMessage("row is not valid JSON")
+}
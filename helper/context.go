@@ -0,0 +1,37 @@
+package helper
+
+import (
+	"context"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Type of context key, so not conflict with key from other package
+type contextKey string
+
+const (
+	principalKey    contextKey = "principal"
+	listResponseKey contextKey = "list_response"
+)
+
+// Function for save authenticated principal to context
+func WithPrincipal(ctx context.Context, principal domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// Function for get authenticated principal from context
+func PrincipalFromContext(ctx context.Context) (domain.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(domain.Principal)
+	return principal, ok
+}
+
+// Function for mark request that respond list of category, so it can be written with encoder for list, e.g. csv
+func WithListResponse(ctx context.Context) context.Context {
+	return context.WithValue(ctx, listResponseKey, true)
+}
+
+// Function for check whether request respond list of category
+func IsListResponse(ctx context.Context) bool {
+	list, _ := ctx.Value(listResponseKey).(bool)
+	return list
+}
@@ -0,0 +1,270 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Media type of request and response body
+const (
+	JSONMediaType        = "application/json"
+	XMLMediaType         = "application/xml"
+	MessagePackMediaType = "application/msgpack"
+	ProblemMediaType     = "application/problem+json" // Error response with format RFC 7807
+)
+
+// Encoder for write response body with one media type
+type Encoder interface {
+	Encode(writer io.Writer, response interface{}) error
+}
+
+// Decoder for read request body with one media type, unknown field is not allowed
+// and error of request body is returned as RequestBodyError
+type Decoder interface {
+	Decode(reader io.Reader, result interface{}) error
+}
+
+// Error when response can not be encoded with the media type, e.g. csv for response
+// that not category, response is encoded as JSON instead
+var ErrNotEncodable = errors.New("response can not be encoded with the media type")
+
+// Encoder with its media type, order of registration is the preference when client accept many media type
+type mediaTypeEncoder struct {
+	mediaType string
+	encoder   Encoder
+	listOnly  bool // Only used for response with list of category
+}
+
+var (
+	encoders []mediaTypeEncoder
+	decoders []mediaTypeDecoder
+)
+
+// Decoder with its media type, order of registration is the order in error message
+type mediaTypeDecoder struct {
+	mediaType string
+	decoder   Decoder
+}
+
+// Register encoder and decoder of built in media type, JSON is the default
+func init() {
+	RegisterEncoder(JSONMediaType, jsonCodec{})
+	RegisterEncoder(XMLMediaType, xmlCodec{})
+	RegisterEncoder(MessagePackMediaType, messagePackCodec{})
+	RegisterListEncoder(CSVMediaType, categoryListEncoder{newWriter: NewCategoryCSVWriter})
+	RegisterListEncoder(NDJSONMediaType, categoryListEncoder{newWriter: NewCategoryNDJSONWriter})
+
+	RegisterDecoder(JSONMediaType, jsonCodec{})
+	RegisterDecoder(XMLMediaType, xmlCodec{})
+	RegisterDecoder(MessagePackMediaType, messagePackCodec{})
+}
+
+// Function for register encoder of media type, encoder of the same media type is replaced.
+// Must be called before server is started.
+func RegisterEncoder(mediaType string, encoder Encoder) {
+	registerEncoder(mediaTypeEncoder{mediaType: mediaType, encoder: encoder})
+}
+
+// Function for register encoder of media type that only used for response with list of category, e.g. csv.
+// Request with other response that only accept this media type is rejected before processed.
+// Must be called before server is started.
+func RegisterListEncoder(mediaType string, encoder Encoder) {
+	registerEncoder(mediaTypeEncoder{mediaType: mediaType, encoder: encoder, listOnly: true})
+}
+
+func registerEncoder(registered mediaTypeEncoder) {
+	for i := range encoders {
+		if encoders[i].mediaType == registered.mediaType {
+			encoders[i] = registered
+			return
+		}
+	}
+	encoders = append(encoders, registered)
+}
+
+// Function for register decoder of media type, decoder of the same media type is replaced.
+// Must be called before server is started.
+func RegisterDecoder(mediaType string, decoder Decoder) {
+	for i := range decoders {
+		if decoders[i].mediaType == mediaType {
+			decoders[i].decoder = decoder
+			return
+		}
+	}
+	decoders = append(decoders, mediaTypeDecoder{mediaType: mediaType, decoder: decoder})
+}
+
+// Function for get media type of all encoder that can be used for response, ordered by preference.
+// Encoder for list of category is only included when list is true.
+func EncoderMediaTypes(list bool) []string {
+	var mediaTypes []string
+	for _, registered := range encoders {
+		if list || !registered.listOnly {
+			mediaTypes = append(mediaTypes, registered.mediaType)
+		}
+	}
+	return mediaTypes
+}
+
+// Function for get media type of all decoder
+func DecoderMediaTypes() []string {
+	var mediaTypes []string
+	for _, registered := range decoders {
+		mediaTypes = append(mediaTypes, registered.mediaType)
+	}
+	return mediaTypes
+}
+
+// Function for find decoder of media type
+func FindDecoder(mediaType string) (Decoder, bool) {
+	for _, registered := range decoders {
+		if registered.mediaType == mediaType {
+			return registered.decoder, true
+		}
+	}
+	return nil, false
+}
+
+// One media range in header Accept, e.g. application/json;q=0.5
+type MediaRange struct {
+	Type    string // Media type in lower case, may contain wildcard e.g. */* or text/*
+	Quality float64
+}
+
+// Function for parse header Accept, media range without quality has quality 1
+func ParseAccept(accept string) []MediaRange {
+	var mediaRanges []MediaRange
+	for _, value := range strings.Split(accept, ",") {
+		params := strings.Split(value, ";")
+		mediaRange := MediaRange{Type: strings.ToLower(strings.TrimSpace(params[0])), Quality: 1}
+		if mediaRange.Type == "" {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && strings.EqualFold(param[:2], "q=") {
+				if quality, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					mediaRange.Quality = quality
+				}
+			}
+		}
+		mediaRanges = append(mediaRanges, mediaRange)
+	}
+
+	return mediaRanges
+}
+
+// Function for get quality of media type from the most specific media range that match
+func mediaTypeQuality(mediaType string, mediaRanges []MediaRange) (float64, bool) {
+	bestSpecificity, quality := -1, 0.0
+	for _, mediaRange := range mediaRanges {
+		specificity := -1
+		switch {
+		case mediaRange.Type == mediaType:
+			specificity = 2
+		case mediaRange.Type == "*/*":
+			specificity = 0
+		case strings.HasSuffix(mediaRange.Type, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange.Type, "*")):
+			specificity = 1
+		}
+		if specificity > bestSpecificity {
+			bestSpecificity, quality = specificity, mediaRange.Quality
+		}
+	}
+
+	return quality, bestSpecificity >= 0
+}
+
+// Function for check whether header Accept accept one media type, e.g. for response that only has
+// one media type. Header Accept that is empty or only refuse media type accept all media type.
+func AcceptMediaType(accept string, mediaType string) bool {
+	mediaRanges := ParseAccept(accept)
+	accepted := false
+	for _, mediaRange := range mediaRanges {
+		accepted = accepted || mediaRange.Quality > 0
+	}
+	if !accepted {
+		return true
+	}
+
+	quality, ok := mediaTypeQuality(mediaType, mediaRanges)
+	return ok && quality > 0
+}
+
+// Function for choose encoder from header Accept, media type with the highest quality is chosen and
+// the first registered encoder is chosen when quality is the same. Header Accept that is empty or only
+// refuse media type use JSON. Client that accept problem can read JSON, so it is chosen when no other
+// media type is accepted. Encoder for list of category is only chosen when list is true.
+// Return false when no encoder is accepted.
+func NegotiateEncoder(accept string, list bool) (string, Encoder, bool) {
+	candidates := negotiateEncoders(accept, list)
+	if len(candidates) == 0 {
+		return "", nil, false
+	}
+
+	return candidates[0].mediaType, candidates[0].encoder, true
+}
+
+// Function for get all encoder accepted in header Accept, ordered by the same preference as NegotiateEncoder
+func negotiateEncoders(accept string, list bool) []mediaTypeEncoder {
+	mediaRanges := ParseAccept(accept)
+	accepted := false
+	for _, mediaRange := range mediaRanges {
+		accepted = accepted || mediaRange.Quality > 0
+	}
+	if !accepted {
+		return encoders[:1]
+	}
+
+	// (1) Find quality of each encoder, keep order of registration for the same quality
+	type candidate struct {
+		mediaTypeEncoder
+		quality float64
+	}
+	var candidates []candidate
+	for _, registered := range encoders {
+		if registered.listOnly && !list {
+			continue
+		}
+		if quality, ok := mediaTypeQuality(registered.mediaType, mediaRanges); ok && quality > 0 {
+			candidates = append(candidates, candidate{registered, quality})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	if len(candidates) > 0 {
+		acceptedEncoders := make([]mediaTypeEncoder, len(candidates))
+		for i, candidate := range candidates {
+			acceptedEncoders[i] = candidate.mediaTypeEncoder
+		}
+		return acceptedEncoders
+	}
+
+	// (2) Use JSON for client that only accept problem
+	for _, mediaRange := range mediaRanges {
+		if mediaRange.Type == ProblemMediaType && mediaRange.Quality > 0 {
+			return []mediaTypeEncoder{{mediaType: JSONMediaType, encoder: jsonCodec{}}}
+		}
+	}
+
+	return nil
+}
+
+// Codec for JSON, the default media type
+type jsonCodec struct{}
+
+// Function Encode with follow the contract encoder
+func (codec jsonCodec) Encode(writer io.Writer, response interface{}) error {
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// Function Decode with follow the contract decoder
+func (codec jsonCodec) Decode(reader io.Reader, result interface{}) error {
+	return decodeJSON(reader, result)
+}
@@ -0,0 +1,23 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jabutech/go-crud-restful-api/model/web"
+)
+
+// Media type of Server-Sent Events
+const EventStreamMediaType = "text/event-stream"
+
+// Function for write category event with format Server-Sent Events, data is written as JSON in one line
+func WriteCategoryEvent(writer io.Writer, event web.CategoryEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+	return err
+}
@@ -1,28 +1,154 @@
 package helper
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"strings"
+	"time"
 )
 
-// Function for handle decode request body
-func ReadFromRequestBody(request *http.Request, result interface{}) {
-	// (1) Decode request
-	decoder := json.NewDecoder(request.Body)
+// Error of request body that can not be decoded, message can be shown to client
+type RequestBodyError struct {
+	Message              Message
+	UnsupportedMediaType bool // Content type of request body is not supported
+}
+
+func (err RequestBodyError) Error() string {
+	return err.Message.String()
+}
+
+// Function for handle decode request body with decoder of its content type, request without content type
+// is decoded as json. Unknown field and data after the value is not allowed.
+func ReadFromRequestBody(request *http.Request, result interface{}) error {
+	// (1) Find decoder with content type of request body
+	var decoder Decoder = jsonCodec{}
+	if contentType := request.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		found := false
+		if err == nil {
+			decoder, found = FindDecoder(mediaType)
+		}
+		if !found {
+			return RequestBodyError{
+				Message:              NewMessage("content type {0} is not supported, use {1}", contentType, strings.Join(DecoderMediaTypes(), ", ")),
+				UnsupportedMediaType: true,
+			}
+		}
+	}
+
 	// (2) Decode request to category request struct
-	err := decoder.Decode(result)
-	// (4) If error, handle with helper
-	PanicErr(err)
+	return decoder.Decode(request.Body, result)
+}
+
+// Function for decode json document with the same rule as request body, e.g. document after patched
+func DecodeJSON(document []byte, result interface{}) error {
+	return decodeJSON(bytes.NewReader(document), result)
+}
+
+// Function for decode json, unknown field and data after the json value is not allowed
+func decodeJSON(reader io.Reader, result interface{}) error {
+	// (1) Decode json
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	// (2) Return error if failed
+	if err := decoder.Decode(result); err != nil {
+		return RequestBodyError{Message: jsonErrorMessage(err)}
+	}
+
+	// (3) Json must only contain one json value
+	if _, err := decoder.Token(); err != io.EOF {
+		return RequestBodyError{Message: NewMessage("request body must only contain one JSON value")}
+	}
+
+	return nil
 }
 
-// function for handle encode response body
-func WriteToResponseBody(writer http.ResponseWriter, response interface{}) {
-	// (1) Add header
-	writer.Header().Add("Content-Type", "application/json")
-	// (8) Create encode
-	encoder := json.NewEncoder(writer)
-	// (9) Encode web response
-	err := encoder.Encode(response)
-	// (10) If error, handle with helper
+// Function for create message of error from json decoder
+func jsonErrorMessage(err error) Message {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	var timeError *time.ParseError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return NewMessage("request body is required")
+	case errors.As(err, &syntaxError), errors.Is(err, io.ErrUnexpectedEOF):
+		return NewMessage("request body is not valid JSON")
+	case errors.As(err, &typeError) && typeError.Field != "":
+		return NewMessage("field {0} in request body must be {1}", typeError.Field, jsonTypeName(typeError.Type.Kind().String()))
+	case errors.As(err, &typeError):
+		return NewMessage("request body must be {0}", jsonTypeName(typeError.Type.Kind().String()))
+	case errors.As(err, &timeError):
+		return NewMessage("time in request body must use format RFC 3339")
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return NewMessage("field {0} in request body is not known", strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`))
+	default:
+		return NewMessage("request body is not valid JSON")
+	}
+}
+
+// Function for get name of json type from kind of go type
+func jsonTypeName(kind string) string {
+	switch {
+	case kind == "string":
+		return "a string"
+	case kind == "bool":
+		return "a boolean"
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"), strings.HasPrefix(kind, "float"):
+		return "a number"
+	case kind == "slice", kind == "array":
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// function for handle encode response body with status 200
+func WriteToResponseBody(writer http.ResponseWriter, request *http.Request, response interface{}) {
+	WriteResponse(writer, request, http.StatusOK, response)
+}
+
+// Function for handle encode response body with media type from header Accept, then write it with status code.
+// The next accepted media type is used when response can not be encoded, e.g. csv for response that not category.
+// Header Accept is already checked by content negotiation middleware before the request is processed, so response
+// that still can not be encoded, e.g. error response, is written as json.
+func WriteResponse(writer http.ResponseWriter, request *http.Request, code int, response interface{}) {
+	// (1) Encode response before header is written with the first accepted encoder that can encode it
+	mediaType, body, err := encodeResponse(negotiateEncoders(request.Header.Get("Accept"), IsListResponse(request.Context())), response)
+	if errors.Is(err, ErrNotEncodable) {
+		mediaType, body, err = encodeResponse([]mediaTypeEncoder{{mediaType: JSONMediaType, encoder: jsonCodec{}}}, response)
+	}
+	// (2) If error, handle with helper
 	PanicErr(err)
+
+	// (3) Add header, text media type is always utf-8
+	if strings.HasPrefix(mediaType, "text/") {
+		mediaType += "; charset=utf-8"
+	}
+	writer.Header().Set("Content-Type", mediaType)
+	writer.Header().Add("Vary", "Accept")
+	writer.WriteHeader(code)
+
+	// (4) Write encoded response
+	_, err = writer.Write(body.Bytes())
+	PanicErr(err)
+}
+
+// Function for encode response with the first encoder that can encode it, return ErrNotEncodable when no encoder can encode it
+func encodeResponse(candidates []mediaTypeEncoder, response interface{}) (string, *bytes.Buffer, error) {
+	body := &bytes.Buffer{}
+	for _, candidate := range candidates {
+		body.Reset()
+		err := candidate.encoder.Encode(body, response)
+		if !errors.Is(err, ErrNotEncodable) {
+			return candidate.mediaType, body, err
+		}
+	}
+
+	return "", nil, ErrNotEncodable
 }
@@ -0,0 +1,32 @@
+package helper
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Message that can be shown to user and translated. Key is the message in english and also key of
+// the message in catalog of each language, `{0}`, `{1}` in key is replaced with argument in the same order.
+type Message struct {
+	Key  string
+	Args []string
+}
+
+// Function for create message with key and argument
+func NewMessage(key string, args ...string) Message {
+	return Message{Key: key, Args: args}
+}
+
+// Function for create message in english, argument is written to its placeholder
+func (message Message) String() string {
+	if len(message.Args) == 0 {
+		return message.Key
+	}
+
+	pairs := make([]string, 0, len(message.Args)*2)
+	for i, arg := range message.Args {
+		pairs = append(pairs, "{"+strconv.Itoa(i)+"}", arg)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(message.Key)
+}
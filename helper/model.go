@@ -1,17 +1,49 @@
 package helper
 
 import (
+	"time"
+
 	"github.com/jabutech/go-crud-restful-api/model/domain"
 	"github.com/jabutech/go-crud-restful-api/model/web"
 )
 
 func ToCategoryResponse(category domain.Category) web.CategoryResponse {
 	return web.CategoryResponse{
-		Id:   category.Id,
-		Name: category.Name,
+		Id:        category.Id,
+		Name:      category.Name,
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+		CreatedBy: category.CreatedBy,
+		UpdatedBy: category.UpdatedBy,
+		DeletedAt: timePointer(category.DeletedAt),
+		Version:   category.Version,
+		ParentId:  idPointer(category.ParentId),
 	}
 }
 
+func ToCategoryTreeResponses(categories []domain.Category) []web.CategoryTreeResponse {
+	// (1) Group categories by parent, categories is ordered so children keep the order
+	children := map[int][]domain.Category{}
+	for _, category := range categories {
+		children[category.ParentId] = append(children[category.ParentId], category)
+	}
+
+	// (2) Create tree from root category
+	var toTree func(parentId int) []web.CategoryTreeResponse
+	toTree = func(parentId int) []web.CategoryTreeResponse {
+		trees := []web.CategoryTreeResponse{}
+		for _, category := range children[parentId] {
+			trees = append(trees, web.CategoryTreeResponse{
+				CategoryResponse: ToCategoryResponse(category),
+				Children:         toTree(category.Id),
+			})
+		}
+		return trees
+	}
+
+	return toTree(0)
+}
+
 func ToCategoryResponses(categories []domain.Category) []web.CategoryResponse {
 	// (3) Create new variable
 	var categoryResponses []web.CategoryResponse
@@ -23,3 +55,42 @@ func ToCategoryResponses(categories []domain.Category) []web.CategoryResponse {
 
 	return categoryResponses
 }
+
+func ToApiKeyResponse(apiKey domain.ApiKey) web.ApiKeyResponse {
+	return web.ApiKeyResponse{
+		Id:         apiKey.Id,
+		Name:       apiKey.Name,
+		KeyPrefix:  apiKey.KeyPrefix,
+		CreatedAt:  apiKey.CreatedAt,
+		LastUsedAt: timePointer(apiKey.LastUsedAt),
+		ExpiresAt:  timePointer(apiKey.ExpiresAt),
+		Revoked:    apiKey.Revoked,
+		Scopes:     apiKey.Scopes,
+	}
+}
+
+func ToApiKeyResponses(apiKeys []domain.ApiKey) []web.ApiKeyResponse {
+	var apiKeyResponses []web.ApiKeyResponse
+
+	for _, apiKey := range apiKeys {
+		apiKeyResponses = append(apiKeyResponses, ToApiKeyResponse(apiKey))
+	}
+
+	return apiKeyResponses
+}
+
+// Function for convert zero time to nil, so encoded as null in response
+func timePointer(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// Function for convert id 0 to nil, so encoded as null in response
+func idPointer(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
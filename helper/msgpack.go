@@ -0,0 +1,56 @@
+package helper
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec for MessagePack, tag json is used for name of field so it is the same as JSON
+type messagePackCodec struct{}
+
+// Function Encode with follow the contract encoder
+func (codec messagePackCodec) Encode(writer io.Writer, response interface{}) error {
+	encoder := msgpack.NewEncoder(writer)
+	encoder.SetCustomStructTag("json")
+	return encoder.Encode(response)
+}
+
+// Function Decode with follow the contract decoder, unknown field and data after the value is not allowed
+func (codec messagePackCodec) Decode(reader io.Reader, result interface{}) error {
+	// (1) Read all request body, so data after the value can be checked
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return RequestBodyError{Message: NewMessage("request body is required")}
+	}
+	bodyReader := bytes.NewReader(body)
+
+	// (2) Decode MessagePack
+	decoder := msgpack.NewDecoder(bodyReader)
+	decoder.SetCustomStructTag("json")
+	decoder.DisallowUnknownFields(true)
+	if err := decoder.Decode(result); err != nil {
+		return RequestBodyError{Message: messagePackErrorMessage(err)}
+	}
+
+	// (3) MessagePack must only contain one value
+	if bodyReader.Len() > 0 {
+		return RequestBodyError{Message: NewMessage("request body must only contain one MessagePack value")}
+	}
+
+	return nil
+}
+
+// Function for create message of error from MessagePack decoder
+func messagePackErrorMessage(err error) Message {
+	if strings.HasPrefix(err.Error(), "msgpack: unknown field ") {
+		return NewMessage("field {0} in request body is not known", strings.Trim(strings.TrimPrefix(err.Error(), "msgpack: unknown field "), `"`))
+	}
+
+	return NewMessage("request body is not valid MessagePack")
+}
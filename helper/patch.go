@@ -0,0 +1,363 @@
+package helper
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media type of patch supported by request PATCH
+const (
+	MergePatchMediaType = "application/merge-patch+json" // JSON Merge Patch, RFC 7396
+	JSONPatchMediaType  = "application/json-patch+json"  // JSON Patch, RFC 6902
+)
+
+// Patch that change json document, e.g. json of category
+type Patch interface {
+	Apply(document []byte) ([]byte, error)
+}
+
+// Error when patch can not be applied to the document, e.g. path is not found
+type PatchError struct {
+	Message Message
+}
+
+func (err PatchError) Error() string {
+	return err.Message.String()
+}
+
+// Patch with format JSON Merge Patch, field with value null is removed
+type MergePatch json.RawMessage
+
+// Patch with format JSON Patch, operation is applied in order
+type JSONPatch []PatchOperation
+
+// One operation of JSON Patch
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  *string         `json:"from"` // Required for move and copy
+	Value json.RawMessage `json:"value"`
+}
+
+// Function for decode patch from request body, format of patch is chosen by content type
+func ReadPatchFromRequestBody(request *http.Request) (Patch, error) {
+	// (1) Check content type of request body
+	contentType := request.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != MergePatchMediaType && mediaType != JSONPatchMediaType) {
+		message := NewMessage("content type {0} is not supported, use {1}", contentType, MergePatchMediaType+", "+JSONPatchMediaType)
+		if contentType == "" {
+			message = NewMessage("content type is required, use {0}", MergePatchMediaType+", "+JSONPatchMediaType)
+		}
+		return nil, RequestBodyError{Message: message, UnsupportedMediaType: true}
+	}
+
+	// (2) Merge patch can be any json value
+	if mediaType == MergePatchMediaType {
+		var patch json.RawMessage
+		if err := decodeJSON(request.Body, &patch); err != nil {
+			return nil, err
+		}
+		return MergePatch(patch), nil
+	}
+
+	// (3) JSON Patch must be array of operation with required member
+	var patch JSONPatch
+	decoder := json.NewDecoder(request.Body)
+	if err := decoder.Decode(&patch); err != nil {
+		return nil, RequestBodyError{Message: jsonErrorMessage(err)}
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, RequestBodyError{Message: NewMessage("request body must only contain one JSON value")}
+	}
+
+	for _, operation := range patch {
+		switch operation.Op {
+		case "add", "replace", "test":
+			if operation.Value == nil {
+				return nil, RequestBodyError{Message: NewMessage("operation {0} of JSON Patch requires {1}", operation.Op, "value")}
+			}
+		case "move", "copy":
+			if operation.From == nil {
+				return nil, RequestBodyError{Message: NewMessage("operation {0} of JSON Patch requires {1}", operation.Op, "from")}
+			}
+			if _, err := parsePointer(*operation.From); err != nil {
+				return nil, RequestBodyError{Message: NewMessage("path {0} of JSON Patch is not valid", *operation.From)}
+			}
+		case "remove":
+		default:
+			return nil, RequestBodyError{Message: NewMessage("operation {0} of JSON Patch is not supported", operation.Op)}
+		}
+		if _, err := parsePointer(operation.Path); err != nil {
+			return nil, RequestBodyError{Message: NewMessage("path {0} of JSON Patch is not valid", operation.Path)}
+		}
+	}
+
+	return patch, nil
+}
+
+// Function Apply with follow the contract patch
+func (patch MergePatch) Apply(document []byte) ([]byte, error) {
+	var target, mergePatch interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &mergePatch); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(applyMergePatch(target, mergePatch))
+}
+
+// Function for merge patch to target, same as pseudo code in RFC 7396
+func applyMergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = applyMergePatch(targetObject[name], value)
+		}
+	}
+
+	return targetObject
+}
+
+// Function Apply with follow the contract patch, document is not changed when one of operation failed
+func (patch JSONPatch) Apply(document []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, err
+	}
+
+	for _, operation := range patch {
+		var err error
+		if target, err = operation.apply(target); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+// Function for apply one operation of JSON Patch to target, return the changed target
+func (operation PatchOperation) apply(target interface{}) (interface{}, error) {
+	path, _ := parsePointer(operation.Path)
+	notFound := PatchError{Message: NewMessage("path {0} of JSON Patch is not found", operation.Path)}
+
+	var fromPath string
+	if operation.From != nil {
+		fromPath = *operation.From
+	}
+	from, _ := parsePointer(fromPath)
+	fromNotFound := PatchError{Message: NewMessage("path {0} of JSON Patch is not found", fromPath)}
+
+	var value interface{}
+	if operation.Value != nil {
+		if err := json.Unmarshal(operation.Value, &value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch operation.Op {
+	case "add":
+		return pointerAdd(target, path, value, notFound)
+	case "remove":
+		target, _, err := pointerRemove(target, path, notFound)
+		return target, err
+	case "replace":
+		target, _, err := pointerRemove(target, path, notFound)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(target, path, value, notFound)
+	case "move":
+		// Value can not be moved to its own child
+		if strings.HasPrefix(operation.Path+"/", fromPath+"/") && operation.Path != fromPath {
+			return nil, PatchError{Message: NewMessage("path {0} of JSON Patch is not valid", operation.Path)}
+		}
+		target, value, err := pointerRemove(target, from, fromNotFound)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(target, path, value, notFound)
+	case "copy":
+		value, err := pointerGet(target, from, fromNotFound)
+		if err != nil {
+			return nil, err
+		}
+		// Copy value, so changing the copy not change the original value
+		copied, _ := json.Marshal(value)
+		json.Unmarshal(copied, &value)
+		return pointerAdd(target, path, value, notFound)
+	default:
+		current, err := pointerGet(target, path, notFound)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, PatchError{Message: NewMessage("test of path {0} in JSON Patch failed", operation.Path)}
+		}
+		return target, nil
+	}
+}
+
+// Function for split JSON Pointer (RFC 6901) to token, empty pointer is the whole document
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, PatchError{Message: NewMessage("path {0} of JSON Patch is not valid", pointer)}
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// Function for convert token to index of array, "-" is index after the last item when allowed
+func arrayIndex(token string, length int, allowEnd bool) (int, bool) {
+	if token == "-" && allowEnd {
+		return length, true
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > length || (index == length && !allowEnd) {
+		return 0, false
+	}
+
+	return index, true
+}
+
+// Function for get value in target at path
+func pointerGet(target interface{}, path []string, notFound error) (interface{}, error) {
+	for _, token := range path {
+		switch node := target.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, notFound
+			}
+			target = value
+		case []interface{}:
+			index, ok := arrayIndex(token, len(node), false)
+			if !ok {
+				return nil, notFound
+			}
+			target = node[index]
+		default:
+			return nil, notFound
+		}
+	}
+
+	return target, nil
+}
+
+// Function for add value to target at path, item of array is inserted at the index
+func pointerAdd(target interface{}, path []string, value interface{}, notFound error) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+	switch node := target.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			node[token] = value
+			return node, nil
+		}
+		child, ok := node[token]
+		if !ok {
+			return nil, notFound
+		}
+		child, err := pointerAdd(child, path[1:], value, notFound)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = child
+		return node, nil
+	case []interface{}:
+		index, ok := arrayIndex(token, len(node), len(path) == 1)
+		if !ok {
+			return nil, notFound
+		}
+		if len(path) == 1 {
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		child, err := pointerAdd(node[index], path[1:], value, notFound)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = child
+		return node, nil
+	default:
+		return nil, notFound
+	}
+}
+
+// Function for remove value from target at path, return the changed target and the removed value
+func pointerRemove(target interface{}, path []string, notFound error) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, target, nil
+	}
+
+	token := path[0]
+	switch node := target.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, nil, notFound
+		}
+		if len(path) == 1 {
+			delete(node, token)
+			return node, child, nil
+		}
+		child, removed, err := pointerRemove(child, path[1:], notFound)
+		if err != nil {
+			return nil, nil, err
+		}
+		node[token] = child
+		return node, removed, nil
+	case []interface{}:
+		index, ok := arrayIndex(token, len(node), false)
+		if !ok {
+			return nil, nil, notFound
+		}
+		if len(path) == 1 {
+			removed := node[index]
+			return append(node[:index], node[index+1:]...), removed, nil
+		}
+		child, removed, err := pointerRemove(node[index], path[1:], notFound)
+		if err != nil {
+			return nil, nil, err
+		}
+		node[index] = child
+		return node, removed, nil
+	default:
+		return nil, nil, notFound
+	}
+}
@@ -0,0 +1,11 @@
+package helper
+
+import "strings"
+
+// Function for cut text around the first separator, same as strings.Cut in go 1.18
+func CutString(text string, separator string) (string, string, bool) {
+	if i := strings.Index(text, separator); i >= 0 {
+		return text[:i], text[i+len(separator):], true
+	}
+	return text, "", false
+}
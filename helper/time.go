@@ -0,0 +1,45 @@
+package helper
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layout of DATETIME returned by MySQL when connection not use parseTime=true
+const mysqlDateTimeLayout = "2006-01-02 15:04:05.999999"
+
+// Time from database that can be null. Support scan from time.Time
+// and from text of MySQL DATETIME, so DATABASE_URL not need parseTime=true
+type NullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+// Function Scan with follow the contract sql.Scanner
+func (nullTime *NullTime) Scan(value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		nullTime.Time, nullTime.Valid = time.Time{}, false
+		return nil
+	case time.Time:
+		nullTime.Time, nullTime.Valid = value.UTC(), true
+		return nil
+	case []byte:
+		return nullTime.parse(string(value))
+	case string:
+		return nullTime.parse(value)
+	default:
+		return fmt.Errorf("cannot scan %T into NullTime", value)
+	}
+}
+
+// Function for parse text of MySQL DATETIME as UTC
+func (nullTime *NullTime) parse(value string) error {
+	parsed, err := time.Parse(mysqlDateTimeLayout, value)
+	if err != nil {
+		return err
+	}
+
+	nullTime.Time, nullTime.Valid = parsed, true
+	return nil
+}
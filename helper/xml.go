@@ -0,0 +1,235 @@
+package helper
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Codec for XML. Response is encoded from its JSON form, so name of element is the same as name of
+// field in JSON: root element is response, object field is element and array item is element item.
+// Request body is converted to JSON with type of result, so it is decoded with the same rule as JSON.
+type xmlCodec struct{}
+
+// Name of element for root of response and each item of array
+const (
+	xmlRootElement = "response"
+	xmlItemElement = "item"
+)
+
+// Function Encode with follow the contract encoder
+func (codec xmlCodec) Encode(writer io.Writer, response interface{}) error {
+	// (1) Encode response to JSON, so tag json is used for name of element
+	document, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	// (2) Write each JSON value as element
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	if err := writeXMLElement(encoder, decoder, xmlRootElement); err != nil {
+		return err
+	}
+
+	return encoder.Flush()
+}
+
+// Function for write the next JSON value as element with name, null value is not written
+func writeXMLElement(encoder *xml.Encoder, decoder *json.Decoder, name string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	switch token := token.(type) {
+	case nil:
+		return nil
+	case json.Delim:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for decoder.More() {
+			name := xmlItemElement
+			if token == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				name = key.(string)
+			}
+			if err := writeXMLElement(encoder, decoder, name); err != nil {
+				return err
+			}
+		}
+		// Read end of object or array
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		return encoder.EncodeToken(start.End())
+	default:
+		return encoder.EncodeElement(fmt.Sprint(token), start)
+	}
+}
+
+// Element of XML document with its text and child element
+type xmlNode struct {
+	name     string
+	text     string
+	children []*xmlNode
+}
+
+// Function Decode with follow the contract decoder, name of root element is not checked
+func (codec xmlCodec) Decode(reader io.Reader, result interface{}) error {
+	// (1) Read XML document
+	root, err := readXMLDocument(reader)
+	if err != nil {
+		return err
+	}
+
+	// (2) Convert to JSON with type of result, then decode as JSON
+	document, err := json.Marshal(root.value(reflect.TypeOf(result).Elem()))
+	if err != nil {
+		return err
+	}
+
+	return decodeJSON(bytes.NewReader(document), result)
+}
+
+// Function for read root element of XML document with all its child element
+func readXMLDocument(reader io.Reader) (*xmlNode, error) {
+	decoder := xml.NewDecoder(reader)
+	var root *xmlNode
+	var parents []*xmlNode
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, RequestBodyError{Message: NewMessage("request body is not valid XML")}
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if root != nil && len(parents) == 0 {
+				return nil, RequestBodyError{Message: NewMessage("request body must only contain one XML element")}
+			}
+			node := &xmlNode{name: token.Name.Local}
+			if root == nil {
+				root = node
+			} else {
+				parent := parents[len(parents)-1]
+				parent.children = append(parent.children, node)
+			}
+			parents = append(parents, node)
+		case xml.EndElement:
+			parents = parents[:len(parents)-1]
+		case xml.CharData:
+			if len(parents) > 0 {
+				parents[len(parents)-1].text += string(token)
+			} else if len(bytes.TrimSpace(token)) > 0 {
+				return nil, RequestBodyError{Message: NewMessage("request body is not valid XML")}
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, RequestBodyError{Message: NewMessage("request body is required")}
+	}
+	return root, nil
+}
+
+// Type that decode itself from JSON string, e.g. time.Time
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Function for convert element to JSON value with type of the go value that decode it.
+// Text of element that not valid for the type is kept as string, so decoder return error of its type.
+func (node *xmlNode) value(valueType reflect.Type) interface{} {
+	for valueType.Kind() == reflect.Ptr {
+		// Empty element is null for pointer
+		if node.text == "" && len(node.children) == 0 {
+			return nil
+		}
+		valueType = valueType.Elem()
+	}
+
+	if reflect.PtrTo(valueType).Implements(textUnmarshalerType) {
+		return node.text
+	}
+
+	switch valueType.Kind() {
+	case reflect.Struct:
+		object := map[string]interface{}{}
+		for _, child := range node.children {
+			if field, ok := jsonField(valueType, child.name); ok {
+				object[child.name] = child.value(field.Type)
+			} else {
+				object[child.name] = child.text
+			}
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		array := []interface{}{}
+		for _, child := range node.children {
+			array = append(array, child.value(valueType.Elem()))
+		}
+		return array
+	case reflect.Map:
+		object := map[string]interface{}{}
+		for _, child := range node.children {
+			object[child.name] = child.value(valueType.Elem())
+		}
+		return object
+	case reflect.Interface:
+		if len(node.children) > 0 {
+			return node.value(reflect.TypeOf(map[string]interface{}{}))
+		}
+		return node.text
+	case reflect.String:
+		return node.text
+	}
+
+	// Number and boolean is kept as is when valid, empty element is zero value
+	text := strings.TrimSpace(node.text)
+	if text == "" {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		switch value.(type) {
+		case float64, bool:
+			return json.RawMessage(text)
+		}
+	}
+
+	return node.text
+}
+
+// Function for find field of struct by name in tag json
+func jsonField(structType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tagName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tagName == "-" || field.PkgPath != "" {
+			continue
+		}
+		if tagName == name || (tagName == "" && strings.EqualFold(field.Name, name)) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
@@ -8,30 +8,91 @@ import (
 	"github.com/jabutech/go-crud-restful-api/app"
 	"github.com/jabutech/go-crud-restful-api/controller"
 	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/middleware"
 	"github.com/jabutech/go-crud-restful-api/repository"
 	"github.com/jabutech/go-crud-restful-api/service"
 	"github.com/joho/godotenv"
 
-	"github.com/go-playground/validator"
 	_ "github.com/go-sql-driver/mysql"
 )
 
 func main() {
 
-	// use db
-	db := app.NewDB()
+	// Load file .env
+	godotenv.Load(".env")
+
+	// Run migration command, e.g. `go-crud-restful-api migrate up`
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := app.RunMigrate(app.OpenDB(), os.Args[2:], os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Use validator
-	validate := validator.New()
+	validate := app.NewValidator()
+
+	// Use storage, set STORAGE=memory for run without database
+	var categoryRespository repository.CategoryRepository
+	var apiKeyRepository repository.ApiKeyRepository
+	var idempotencyKeyRepository repository.IdempotencyKeyRepository
+	var txBeginner repository.TxBeginner
+	if os.Getenv("STORAGE") == "memory" {
+		memoryDB := repository.NewMemoryDB()
+		categoryRespository = repository.NewCategoryRepositoryMemory(memoryDB)
+		apiKeyRepository = repository.NewApiKeyRepositoryMemory(memoryDB)
+		idempotencyKeyRepository = repository.NewIdempotencyKeyRepositoryMemory(memoryDB)
+		txBeginner = memoryDB
+	} else {
+		// use db
+		db := app.NewDB()
+		categoryRespository = repository.NewCategoriRepository()
+		apiKeyRepository = repository.NewApiKeyRepository()
+		idempotencyKeyRepository = repository.NewIdempotencyKeyRepository()
+		txBeginner = repository.NewSqlTxBeginner(db)
+	}
+
+	// Load config of category hierarchy
+	categoryTreeConfig, err := app.NewCategoryTreeConfig()
+	helper.PanicErr(err)
+
+	// Publish change of category to client of event stream, recent event is kept for resume
+	categoryEventBufferSize, err := app.NewCategoryEventBufferSize()
+	helper.PanicErr(err)
+	categoryEventBroker := service.NewCategoryEventBroker(categoryEventBufferSize)
 
-	categoryRespository := repository.NewCategoriRepository()
-	categoryService := service.NewCategoryService(categoryRespository, db, validate)
+	unitOfWork := repository.NewUnitOfWork(txBeginner)
+	categoryService := service.NewCategoryService(categoryRespository, unitOfWork, validate, categoryTreeConfig, categoryEventBroker)
 	categoryController := controller.NewCategoryController(categoryService)
+	apiKeyService := service.NewApiKeyService(apiKeyRepository, unitOfWork, validate)
+	apiKeyController := controller.NewApiKeyController(apiKeyService)
 
 	// Use file router
-	router := app.NewRouter(categoryController)
+	router := app.NewRouter(categoryController, apiKeyController)
 
-	// Load file .env
-	godotenv.Load(".env")
+	// Save response of request with header Idempotency-Key, so retried request is not processed twice
+	idempotencyKeyTTL, err := app.NewIdempotencyKeyTTL()
+	helper.PanicErr(err)
+	idempotencyLockTimeout, err := app.NewIdempotencyLockTimeout()
+	helper.PanicErr(err)
+	idempotencyService := service.NewIdempotencyService(idempotencyKeyRepository, unitOfWork, idempotencyKeyTTL, idempotencyLockTimeout)
+
+	// Load API key for authentication, API key issued at runtime is cached for a short time
+	apiKeys, err := app.NewApiKeys()
+	helper.PanicErr(err)
+	apiKeyCacheTTL, err := app.NewApiKeyCacheTTL()
+	helper.PanicErr(err)
+	apiKeyStore := middleware.NewApiKeyCache(apiKeyService, apiKeyCacheTTL)
+	authenticators := []middleware.Authenticator{middleware.NewApiKeyAuthenticator(apiKeys, apiKeyStore)}
+
+	// Accept JWT bearer token when configured
+	jwtVerifier, err := app.NewJwtVerifier()
+	helper.PanicErr(err)
+	if jwtVerifier != nil {
+		authenticators = append(authenticators, middleware.NewJwtAuthenticator(jwtVerifier))
+	}
 
 	// Get variable from env file
 	port := os.Getenv("PORT")
@@ -39,17 +100,18 @@ func main() {
 		port = "8000"
 	}
 
-	// Create server
+	// Create server, request is authenticated before content negotiation and idempotency key is checked
+	handler := middleware.NewContentNegotiationMiddleware(middleware.NewIdempotencyMiddleware(router, idempotencyService))
 	server := http.Server{
 		Addr:    ":" + port,
-		Handler: router,
+		Handler: middleware.NewAuthMiddleware(handler, authenticators...),
 	}
 
 	// If no error, print message url run
 	fmt.Println("App running at http://localhost:" + port)
 
 	// Run server
-	err := server.ListenAndServe()
+	err = server.ListenAndServe()
 	// If error handle with helper
 	helper.PanicErr(err)
 
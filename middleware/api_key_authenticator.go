@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// API key with name for identify the caller
+type ApiKey struct {
+	Name   string
+	Key    string
+	Scopes []string // Granted scope, nil means all scope of category, scope admin must be granted explicitly
+}
+
+// Authenticator for request header `X-API-Key`
+type ApiKeyAuthenticator struct {
+	ApiKeys     []ApiKey    // API key from configuration
+	ApiKeyStore ApiKeyStore // API key issued at runtime, can be nil
+}
+
+func NewApiKeyAuthenticator(apiKeys []ApiKey, apiKeyStore ApiKeyStore) Authenticator {
+	return &ApiKeyAuthenticator{
+		ApiKeys:     apiKeys,
+		ApiKeyStore: apiKeyStore,
+	}
+}
+
+// Function Authenticate with follow the contract authenticator
+func (authenticator *ApiKeyAuthenticator) Authenticate(request *http.Request) (domain.Principal, error) {
+	key := request.Header.Get("X-API-Key")
+	if key == "" {
+		return domain.Principal{}, ErrNoCredential
+	}
+
+	// (1) Check whether key is one of API key from configuration
+	if apiKey, ok := authenticator.findApiKey(key); ok {
+		scopes := apiKey.Scopes
+		if scopes == nil {
+			scopes = domain.CategoryScopes
+		}
+		return domain.Principal{Name: apiKey.Name, Method: "api_key", Scopes: scopes}, nil
+	}
+
+	// (2) Check whether key is issued at runtime
+	if authenticator.ApiKeyStore == nil {
+		return domain.Principal{}, ErrInvalidCredential
+	}
+
+	apiKey, err := authenticator.ApiKeyStore.Authenticate(request.Context(), key)
+
+	var notFoundError exception.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return domain.Principal{}, ErrInvalidCredential
+	}
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	return domain.Principal{Name: apiKey.Name, Method: "api_key", Scopes: apiKey.Scopes}, nil
+}
+
+// Function for find API key with constant time comparison, so time of comparison not leak the key.
+// Key is hashed first, so length of key also not leaked.
+func (authenticator *ApiKeyAuthenticator) findApiKey(key string) (ApiKey, bool) {
+	hash := sha256.Sum256([]byte(key))
+
+	var found ApiKey
+	match := 0
+	for _, apiKey := range authenticator.ApiKeys {
+		apiKeyHash := sha256.Sum256([]byte(apiKey.Key))
+		if subtle.ConstantTimeCompare(hash[:], apiKeyHash[:]) == 1 && match == 0 {
+			found = apiKey
+			match = 1
+		}
+	}
+
+	return found, match == 1
+}
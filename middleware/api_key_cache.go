@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Max entry in cache, the oldest entry is removed when cache is full
+const apiKeyCacheMaxEntries = 10000
+
+// Contract for store that validate API key issued at runtime
+type ApiKeyStore interface {
+	// Return exception.NotFoundError when key is not valid
+	Authenticate(ctx context.Context, key string) (domain.ApiKey, error)
+}
+
+type apiKeyCacheEntry struct {
+	hash      [sha256.Size]byte
+	apiKey    domain.ApiKey
+	expiresAt time.Time
+}
+
+// Cache for API key store, so not every request read the storage.
+// Revoked or rotated key still can be used until the cache entry expired.
+// Key that not found is not cached, so key created after failed request can be used immediately.
+type ApiKeyCache struct {
+	Store   ApiKeyStore
+	TTL     time.Duration
+	mutex   sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // Entry ordered by time saved, the oldest first, so also ordered by expiry time
+}
+
+func NewApiKeyCache(store ApiKeyStore, ttl time.Duration) *ApiKeyCache {
+	return &ApiKeyCache{
+		Store:   store,
+		TTL:     ttl,
+		entries: map[[sha256.Size]byte]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Function Authenticate with follow the contract api key store
+func (cache *ApiKeyCache) Authenticate(ctx context.Context, key string) (domain.ApiKey, error) {
+	// Key is saved as hash, so the key not kept in memory
+	hash := sha256.Sum256([]byte(key))
+	now := time.Now()
+
+	// (1) Use entry in cache when not expired
+	cache.mutex.Lock()
+	var entry apiKeyCacheEntry
+	element, ok := cache.entries[hash]
+	if ok {
+		entry = element.Value.(apiKeyCacheEntry)
+	}
+	cache.mutex.Unlock()
+
+	// (2) If not in cache, get from store. Key that not found is not saved to cache
+	if !ok || now.After(entry.expiresAt) {
+		apiKey, err := cache.Store.Authenticate(ctx, key)
+		if err != nil {
+			cache.remove(hash)
+			return domain.ApiKey{}, err
+		}
+
+		entry = apiKeyCacheEntry{hash: hash, apiKey: apiKey, expiresAt: now.Add(cache.TTL)}
+		cache.save(entry, now)
+	}
+
+	// (3) API key can expire while in cache
+	if !entry.apiKey.Active(now) {
+		return domain.ApiKey{}, exception.NewNotFoundError("api key is not found")
+	}
+
+	return entry.apiKey, nil
+}
+
+// Function for save entry as the newest entry, expired entry is removed first, then the oldest
+// entry when cache is still full
+func (cache *ApiKeyCache) save(entry apiKeyCacheEntry, now time.Time) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.entries[entry.hash]; ok {
+		cache.order.Remove(element)
+		delete(cache.entries, entry.hash)
+	}
+	for front := cache.order.Front(); front != nil; front = cache.order.Front() {
+		oldest := front.Value.(apiKeyCacheEntry)
+		if len(cache.entries) < apiKeyCacheMaxEntries && !now.After(oldest.expiresAt) {
+			break
+		}
+		cache.order.Remove(front)
+		delete(cache.entries, oldest.hash)
+	}
+
+	cache.entries[entry.hash] = cache.order.PushBack(entry)
+}
+
+// Function for remove entry of key that not valid anymore
+func (cache *ApiKeyCache) remove(hash [sha256.Size]byte) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.entries[hash]; ok {
+		cache.order.Remove(element)
+		delete(cache.entries, hash)
+	}
+}
@@ -1,36 +1,73 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/jabutech/go-crud-restful-api/exception"
 	"github.com/jabutech/go-crud-restful-api/helper"
-	"github.com/jabutech/go-crud-restful-api/model/web"
+
+	"github.com/julienschmidt/httprouter"
 )
 
 type AuthMiddleware struct {
-	Handler http.Handler
+	Handler        http.Handler
+	Authenticators []Authenticator // Authentication method, tried in order
+	PublicPaths    map[string]bool // Path that can be accessed without authentication
 }
 
-func NewAuthMiddleware(handler http.Handler) *AuthMiddleware {
-	return &AuthMiddleware{Handler: handler}
+func NewAuthMiddleware(handler http.Handler, authenticators ...Authenticator) *AuthMiddleware {
+	return &AuthMiddleware{
+		Handler:        handler,
+		Authenticators: authenticators,
+		PublicPaths: map[string]bool{
+			"/": true, // Health check
+		},
+	}
 }
 
 func (middleware *AuthMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	// Check whether the request header `X-API-Key` same with "RAHASIA"
-	if "RAHASIA" == request.Header.Get("X-API-Key") {
-		// Yes, Next process
+	// (1) Public path not need authentication
+	if middleware.PublicPaths[request.URL.Path] {
 		middleware.Handler.ServeHTTP(writer, request)
-	} else {
-		// No, resonse error
-		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusUnauthorized)
-
-		webResponse := web.WebResponse{
-			Code:   http.StatusUnauthorized,
-			Status: "UNAUTHORIZED",
+		return
+	}
+
+	// (2) Use the first authenticator that found credential in request
+	for _, authenticator := range middleware.Authenticators {
+		principal, err := authenticator.Authenticate(request)
+		if errors.Is(err, ErrNoCredential) {
+			continue
+		}
+		if errors.Is(err, ErrInvalidCredential) {
+			if challenger, ok := authenticator.(Challenger); ok {
+				writer.Header().Set("WWW-Authenticate", challenger.Challenge())
+			}
+			break
+		}
+		if err != nil {
+			exception.ErrorHandler(writer, request, err)
+			return
 		}
 
-		helper.WriteToResponseBody(writer, webResponse)
+		// Next process with principal in context
+		middleware.Handler.ServeHTTP(writer, request.WithContext(helper.WithPrincipal(request.Context(), principal)))
+		return
+	}
+
+	// (3) No valid credential, resonse error
+	exception.ErrorHandler(writer, request, exception.NewUnauthorizedError("valid api key or bearer token is required"))
+}
+
+// Function for handle route that only can be accessed by principal with the scope
+func RequireScope(scope string, handle httprouter.Handle) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		principal, ok := helper.PrincipalFromContext(request.Context())
+		if !ok || !principal.HasScope(scope) {
+			exception.ErrorHandler(writer, request, exception.NewForbiddenError("scope {0} is required", scope))
+			return
+		}
 
+		handle(writer, request, params)
 	}
 }
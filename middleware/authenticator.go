@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+var (
+	// Request not contain credential for the authenticator, next authenticator is tried
+	ErrNoCredential = errors.New("no credential")
+	// Request contain credential for the authenticator, but credential is not valid
+	ErrInvalidCredential = errors.New("invalid credential")
+)
+
+// Contract for authenticate request with one authentication method
+type Authenticator interface {
+	// Return ErrNoCredential when request not use this method, ErrInvalidCredential
+	// when credential is not valid, and other error when credential can not be checked
+	Authenticate(request *http.Request) (domain.Principal, error)
+}
+
+// Optional contract for authenticator that send challenge in header `WWW-Authenticate`
+// when credential is not valid
+type Challenger interface {
+	Challenge() string
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+)
+
+type ContentNegotiationMiddleware struct {
+	Handler http.Handler
+	Streams map[string]string // Media type of path that only write stream, e.g. "/api/categories/events"
+	Lists   []string          // Path of GET request that respond list of category, * match one segment of path
+}
+
+func NewContentNegotiationMiddleware(handler http.Handler) *ContentNegotiationMiddleware {
+	return &ContentNegotiationMiddleware{
+		Handler: handler,
+		Streams: map[string]string{
+			"/api/categories/events": helper.EventStreamMediaType, // Category event
+		},
+		Lists: []string{
+			"/api/categories",
+			"/api/categories/export",
+			"/api/categories/*/children",
+			"/api/categories/*/ancestors",
+		},
+	}
+}
+
+func (middleware *ContentNegotiationMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	// (1) Stream is only written with its media type
+	if mediaType, ok := middleware.Streams[request.URL.Path]; ok {
+		if !helper.AcceptMediaType(request.Header.Get("Accept"), mediaType) {
+			exception.ErrorHandler(writer, request, exception.NewNotAcceptableError(
+				"none of media type in header Accept is supported, use {0}", mediaType,
+			))
+			return
+		}
+		middleware.Handler.ServeHTTP(writer, request)
+		return
+	}
+
+	// (2) Media type for list of category, e.g. csv, is only used for request that respond list of category
+	list := middleware.isList(request)
+	if list {
+		request = request.WithContext(helper.WithListResponse(request.Context()))
+	}
+
+	// (3) Request is not processed when no media type in header Accept can be written,
+	// so request that change data is not applied when its response can not be written
+	if _, _, ok := helper.NegotiateEncoder(request.Header.Get("Accept"), list); !ok {
+		exception.ErrorHandler(writer, request, exception.NewNotAcceptableError(
+			"none of media type in header Accept is supported, use {0}", strings.Join(helper.EncoderMediaTypes(list), ", "),
+		))
+		return
+	}
+
+	// (4) Next process
+	middleware.Handler.ServeHTTP(writer, request)
+}
+
+// Function for check whether request respond list of category from its method and path
+func (middleware *ContentNegotiationMiddleware) isList(request *http.Request) bool {
+	if request.Method != http.MethodGet {
+		return false
+	}
+
+	segments := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	for _, list := range middleware.Lists {
+		patterns := strings.Split(strings.Trim(list, "/"), "/")
+		if len(patterns) != len(segments) {
+			continue
+		}
+
+		match := true
+		for i, pattern := range patterns {
+			match = match && (pattern == "*" || pattern == segments[i])
+		}
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Header of request with key for retry request without process it twice
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Max length of key in header Idempotency-Key
+const idempotencyKeyMaxLength = 255
+
+// Max size of request body that read for fingerprint when MaxBodySize is not set
+const DefaultIdempotencyMaxBodySize = 1 << 20
+
+// Header of response that saved and written again when response is replayed
+var idempotencyReplayHeaders = []string{"Content-Type", "Content-Language", "ETag", "Location", "Vary"}
+
+// Contract for store that save key and response of request
+type IdempotencyStore interface {
+	// Return saved key with false when key is already used
+	Reserve(ctx context.Context, principal string, key string, fingerprint string) (domain.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, idempotencyKey domain.IdempotencyKey) error
+	Release(ctx context.Context, idempotencyKey domain.IdempotencyKey) error
+}
+
+type IdempotencyMiddleware struct {
+	Handler http.Handler
+	Store   IdempotencyStore
+	Routes  map[string]bool // Method and path that support header Idempotency-Key, e.g. "POST /api/categories"
+	// Max size of request body in bytes, request body is read to memory for fingerprint
+	MaxBodySize int64
+}
+
+func NewIdempotencyMiddleware(handler http.Handler, store IdempotencyStore) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		Handler:     handler,
+		Store:       store,
+		MaxBodySize: DefaultIdempotencyMaxBodySize,
+		Routes: map[string]bool{
+			"POST /api/categories": true, // Create category
+		},
+	}
+}
+
+func (middleware *IdempotencyMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	// (1) Request without key is processed as usual
+	key := request.Header.Get(IdempotencyKeyHeader)
+	if key == "" || !middleware.Routes[request.Method+" "+request.URL.Path] {
+		middleware.Handler.ServeHTTP(writer, request)
+		return
+	}
+	if len(key) > idempotencyKeyMaxLength {
+		exception.ErrorHandler(writer, request, exception.NewBadRequestError("header {0} must be at most {1} characters", "Idempotency-Key", strconv.Itoa(idempotencyKeyMaxLength)))
+		return
+	}
+
+	// (2) Read request body for fingerprint with limited size, then use it again for handler
+	body, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, middleware.MaxBodySize))
+	if err != nil && int64(len(body)) >= middleware.MaxBodySize {
+		exception.ErrorHandler(writer, request, exception.NewRequestEntityTooLargeError("request body must be at most {0} bytes", strconv.FormatInt(middleware.MaxBodySize, 10)))
+		return
+	}
+	if err != nil {
+		exception.ErrorHandler(writer, request, exception.NewBadRequestError("request body can not be read"))
+		return
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	// (3) Reserve key, key is only unique for the same caller
+	principal, _ := helper.PrincipalFromContext(request.Context())
+	principalName := principal.Method + ":" + principal.Name
+	fingerprint := requestFingerprint(request, principalName, body)
+	idempotencyKey, reserved, err := middleware.Store.Reserve(request.Context(), principalName, key, fingerprint)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	// (4) Key that already used is replied with the saved response
+	if !reserved {
+		replayResponse(writer, request, idempotencyKey, fingerprint)
+		return
+	}
+
+	// (5) Process request and save the response. Saved with new context, so response is still saved
+	// when client is disconnected before request is done, e.g. because of timeout.
+	// Key is released when handler panic, so request can be retried with the same key.
+	recorder := &idempotencyRecorder{ResponseWriter: writer}
+	processed := false
+	defer func() {
+		if !processed {
+			middleware.release(request, idempotencyKey)
+		}
+	}()
+	middleware.Handler.ServeHTTP(recorder, request)
+	processed = true
+
+	// (6) Response of server error is not saved, so request can be retried with the same key
+	if recorder.statusCode == 0 || recorder.statusCode >= http.StatusInternalServerError {
+		middleware.release(request, idempotencyKey)
+		return
+	}
+	idempotencyKey.StatusCode = recorder.statusCode
+	idempotencyKey.Header = recorder.header
+	idempotencyKey.Body = recorder.body.Bytes()
+	if err := middleware.Store.Complete(context.Background(), idempotencyKey); err != nil {
+		// Response is already sent, key can be used again after lock timeout
+		log.Printf("%s %s: %v", request.Method, request.URL.Path, err)
+	}
+}
+
+// Function for release key of request that failed
+func (middleware *IdempotencyMiddleware) release(request *http.Request, idempotencyKey domain.IdempotencyKey) {
+	if err := middleware.Store.Release(context.Background(), idempotencyKey); err != nil {
+		// Key can be used again after lock timeout
+		log.Printf("%s %s: %v", request.Method, request.URL.Path, err)
+	}
+}
+
+// Function for write response of key that already used
+func replayResponse(writer http.ResponseWriter, request *http.Request, idempotencyKey domain.IdempotencyKey, fingerprint string) {
+	switch {
+	case idempotencyKey.Fingerprint != fingerprint:
+		exception.ErrorHandler(writer, request, exception.NewUnprocessableEntityError("header Idempotency-Key is already used for other request"))
+	case !idempotencyKey.Completed():
+		exception.ErrorHandler(writer, request, exception.NewConflictError("request with the same Idempotency-Key is still processed"))
+	default:
+		for name, value := range idempotencyKey.Header {
+			writer.Header().Set(name, value)
+		}
+		writer.Header().Set("Idempotent-Replayed", "true")
+		writer.WriteHeader(idempotencyKey.StatusCode)
+		writer.Write(idempotencyKey.Body)
+	}
+}
+
+// Function for create fingerprint of request in hex from method, path, caller, header that change
+// the representation of request or response, and body
+func requestFingerprint(request *http.Request, principal string, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, request.Method+" "+request.URL.RequestURI()+"\n")
+	io.WriteString(hash, principal+"\n")
+	for _, name := range []string{"Content-Type", "Accept", "Accept-Language"} {
+		io.WriteString(hash, name+": "+request.Header.Get(name)+"\n")
+	}
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Writer that keep status code, header and body of response while write it to client
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	header     map[string]string
+	body       bytes.Buffer
+}
+
+func (recorder *idempotencyRecorder) WriteHeader(statusCode int) {
+	if recorder.statusCode == 0 {
+		recorder.statusCode = statusCode
+		recorder.header = map[string]string{}
+		for _, name := range idempotencyReplayHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				recorder.header[name] = value
+			}
+		}
+	}
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *idempotencyRecorder) Write(data []byte) (int, error) {
+	if recorder.statusCode == 0 {
+		recorder.WriteHeader(http.StatusOK)
+	}
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Authenticator for request header `Authorization: Bearer <token>`
+type JwtAuthenticator struct {
+	Verifier *JwtVerifier
+}
+
+func NewJwtAuthenticator(verifier *JwtVerifier) Authenticator {
+	return &JwtAuthenticator{Verifier: verifier}
+}
+
+// Function Authenticate with follow the contract authenticator
+func (authenticator *JwtAuthenticator) Authenticate(request *http.Request) (domain.Principal, error) {
+	// (1) Get token from header, scheme is case insensitive
+	authorization := request.Header.Get("Authorization")
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return domain.Principal{}, ErrNoCredential
+	}
+	token := strings.TrimSpace(authorization[7:])
+
+	// (2) Verify token
+	claims, err := authenticator.Verifier.Verify(token)
+	if err != nil {
+		return domain.Principal{}, ErrInvalidCredential
+	}
+
+	// (3) Use subject as name of principal
+	return domain.Principal{
+		Name:   claims["sub"].(string),
+		Method: "jwt",
+		Scopes: tokenScopes(claims),
+		Claims: claims,
+	}, nil
+}
+
+// Function for get scope from claim `scope` separated with space (RFC 8693),
+// or claim `scp` as array or string
+func tokenScopes(claims map[string]interface{}) []string {
+	var scopes []string
+	for _, name := range []string{"scope", "scp"} {
+		switch value := claims[name].(type) {
+		case string:
+			scopes = append(scopes, strings.Fields(value)...)
+		case []interface{}:
+			for _, item := range value {
+				if scope, ok := item.(string); ok {
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+	}
+	return scopes
+}
+
+// Function Challenge with follow the contract challenger
+func (authenticator *JwtAuthenticator) Challenge() string {
+	return `Bearer error="invalid_token"`
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// Verifier for JWT signed with HS256 or RS256
+type JwtVerifier struct {
+	Issuer     string                    // Required value of claim iss, empty for not check
+	Audience   string                    // Required value of claim aud, empty for not check
+	Leeway     time.Duration             // Allowed clock difference for check exp and nbf
+	HmacSecret []byte                    // Secret for HS256, nil for not accept HS256
+	RsaKeys    map[string]*rsa.PublicKey // Public key for RS256 by key id, empty for not accept RS256
+	Now        func() time.Time          // Current time, can be changed for test
+}
+
+// Header of JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Function for verify JWT, return claims when signature and claims are valid
+func (verifier *JwtVerifier) Verify(token string) (map[string]interface{}, error) {
+	// (1) JWT must have 3 part, header.payload.signature
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jwt: token is malformed")
+	}
+
+	// (2) Decode header
+	var header jwtHeader
+	if err := decodeJwtPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	// (3) Verify signature, algorithm must be one of algorithm configured
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("jwt: signature is malformed")
+	}
+	if err := verifier.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	// (4) Decode claims, number is kept as json.Number for compare time
+	var claims map[string]interface{}
+	if err := decodeJwtPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	// (5) Verify claims
+	if err := verifier.verifyClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Function for decode part of JWT from base64 url json
+func decodeJwtPart(part string, result interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errors.New("jwt: token is malformed")
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(result); err != nil {
+		return errors.New("jwt: token is malformed")
+	}
+
+	return nil
+}
+
+// Function for verify signature with algorithm in header
+func (verifier *JwtVerifier) verifySignature(header jwtHeader, signingInput string, signature []byte) error {
+	switch header.Alg {
+	case "HS256":
+		if verifier.HmacSecret == nil {
+			break
+		}
+		mac := hmac.New(sha256.New, verifier.HmacSecret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("jwt: signature is invalid")
+		}
+		return nil
+	case "RS256":
+		if len(verifier.RsaKeys) == 0 {
+			break
+		}
+		key, err := verifier.rsaKey(header.Kid)
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+			return errors.New("jwt: signature is invalid")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("jwt: algorithm %q is not allowed", header.Alg)
+}
+
+// Function for find RSA key by key id, key id can be empty when only one key is available
+func (verifier *JwtVerifier) rsaKey(kid string) (*rsa.PublicKey, error) {
+	if key, ok := verifier.RsaKeys[kid]; ok {
+		return key, nil
+	}
+
+	if kid == "" && len(verifier.RsaKeys) == 1 {
+		for _, key := range verifier.RsaKeys {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("jwt: key %q is not found", kid)
+}
+
+// Function for verify registered claims
+func (verifier *JwtVerifier) verifyClaims(claims map[string]interface{}) error {
+	now := time.Now()
+	if verifier.Now != nil {
+		now = verifier.Now()
+	}
+
+	// (1) Token must have expired time, and not expired
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return errors.New("jwt: claim exp is required")
+	}
+	if !now.Before(exp.Add(verifier.Leeway)) {
+		return errors.New("jwt: token is expired")
+	}
+
+	// (2) Token can not be used before nbf
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(verifier.Leeway).Before(nbf) {
+		return errors.New("jwt: token is not valid yet")
+	}
+
+	// (3) Check issuer
+	if verifier.Issuer != "" && claims["iss"] != verifier.Issuer {
+		return errors.New("jwt: issuer is invalid")
+	}
+
+	// (4) Check audience, aud can be string or array of string
+	if verifier.Audience != "" && !containsAudience(claims["aud"], verifier.Audience) {
+		return errors.New("jwt: audience is invalid")
+	}
+
+	// (5) Subject is required for identify caller
+	if subject, ok := claims["sub"].(string); !ok || subject == "" {
+		return errors.New("jwt: claim sub is required")
+	}
+
+	return nil
+}
+
+// Function for convert claim numeric date to time
+func numericDate(value interface{}) (time.Time, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// Function for check whether claim aud contains audience
+func containsAudience(value interface{}, audience string) bool {
+	switch value := value.(type) {
+	case string:
+		return value == audience
+	case []interface{}:
+		for _, item := range value {
+			if item == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// JSON Web Key Set, only RSA key is used
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// Function for load RSA public key from JWKS file, key for encryption is ignored
+func LoadJwks(path string) (map[string]*rsa.PublicKey, error) {
+	// (1) Read and decode file
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keySet jwks
+	if err := json.Unmarshal(content, &keySet); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	// (2) Convert every RSA key for signature
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range keySet.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") || (key.Alg != "" && key.Alg != "RS256") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwks: key %q has invalid n", key.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("jwks: key %q has invalid e", key.Kid)
+		}
+
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks: no RSA key for signature")
+	}
+
+	return keys, nil
+}
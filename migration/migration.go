@@ -0,0 +1,118 @@
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// All migration file, name format is <version>_<name>.<up|down>.sql
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// Migration for one version of schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string // Statements for apply migration
+	Down    []string // Statements for rollback migration
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Function for load all migration embedded in binary
+func Embedded() ([]Migration, error) {
+	return Load(files, "sql")
+}
+
+// Function for load migration from directory, sorted by version.
+// Every version must have up and down file.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	// (1) Read all file in directory
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// (2) Group up and down file by version
+	migrations := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration: invalid file name %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		migration, ok := migrations[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			migrations[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration: version %d has different name %s and %s", version, migration.Name, match[2])
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if match[3] == "up" {
+			migration.Up = splitStatements(string(content))
+		} else {
+			migration.Down = splitStatements(string(content))
+		}
+	}
+
+	// (3) Check every version is complete and sort by version
+	var result []Migration
+	for _, migration := range migrations {
+		if migration.Up == nil || migration.Down == nil {
+			return nil, fmt.Errorf("migration: version %d must have up and down file", migration.Version)
+		}
+		result = append(result, *migration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// Function for split sql file into statements, statement is ended with `;` at the end of line.
+// Line comment started with `--` is ignored.
+func splitStatements(content string) []string {
+	statements := []string{}
+
+	var statement strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		statement.WriteString(line)
+		statement.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(statement.String()), ";"))
+			statement.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(statement.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
@@ -0,0 +1,257 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/helper"
+)
+
+// Status of migration version in database
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Dirty     bool // Migration failed in the middle, database must be fixed manually
+}
+
+// Applied version in table schema_migrations
+type appliedVersion struct {
+	appliedAt time.Time
+	dirty     bool
+}
+
+// Migrator for apply and rollback migration, applied version is saved to table schema_migrations.
+// Version that is applied or rolled back is saved as dirty until all of its statement succeed, because
+// MySQL commit each DDL statement implicitly, so statement of migration can not be rolled back when
+// the next statement failed. Migration is not run while a version is dirty.
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+// Function for create migrator with migration embedded in binary
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := Embedded()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// Function for create table schema_migrations when not exists
+func (migrator *Migrator) init(ctx context.Context) error {
+	_, err := migrator.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  applied_at DATETIME NOT NULL,
+  dirty BOOLEAN NOT NULL DEFAULT FALSE,
+  PRIMARY KEY (version)
+) ENGINE = InnoDB`)
+
+	return err
+}
+
+// Function for get all applied version with time applied and dirty state
+func (migrator *Migrator) applied(ctx context.Context) (map[int]appliedVersion, error) {
+	// (1) Create table schema_migrations for the first time
+	if err := migrator.init(ctx); err != nil {
+		return nil, err
+	}
+
+	// (2) Get all applied version
+	rows, err := migrator.DB.QueryContext(ctx, "SELECT version, applied_at, dirty FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedVersion{}
+	for rows.Next() {
+		var version int
+		var appliedAt helper.NullTime
+		var dirty bool
+		if err := rows.Scan(&version, &appliedAt, &dirty); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedVersion{appliedAt: appliedAt.Time, dirty: dirty}
+	}
+
+	return applied, rows.Err()
+}
+
+// Function for get status of all migration
+func (migrator *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, migration := range migrator.Migrations {
+		version, ok := applied[migration.Version]
+		statuses = append(statuses, Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: version.appliedAt,
+			Dirty:     version.dirty,
+		})
+	}
+
+	return statuses, nil
+}
+
+// Function for get latest version of migration
+func (migrator *Migrator) Latest() int {
+	if len(migrator.Migrations) == 0 {
+		return 0
+	}
+
+	return migrator.Migrations[len(migrator.Migrations)-1].Version
+}
+
+// Function for apply all pending migration
+func (migrator *Migrator) Up(ctx context.Context) error {
+	return migrator.To(ctx, migrator.Latest())
+}
+
+// Function for rollback the last applied migration
+func (migrator *Migrator) Down(ctx context.Context) error {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Find the last applied version, then migrate to version before it
+	for i := len(migrator.Migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[migrator.Migrations[i].Version]; ok {
+			if i == 0 {
+				return migrator.To(ctx, 0)
+			}
+			return migrator.To(ctx, migrator.Migrations[i-1].Version)
+		}
+	}
+
+	return nil
+}
+
+// Function for migrate to version, apply pending migration until version
+// and rollback applied migration after version. Version 0 rollback all migration.
+func (migrator *Migrator) To(ctx context.Context, version int) error {
+	// (1) Check version is available
+	if _, ok := migrator.find(version); version != 0 && !ok {
+		return fmt.Errorf("migration: version %d is not found", version)
+	}
+
+	// (2) Get applied version, migration is not run while a version is dirty
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkDirty(applied); err != nil {
+		return err
+	}
+
+	// (3) Apply pending migration until version, from the oldest
+	for _, migration := range migrator.Migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > version {
+			continue
+		}
+		if err := migrator.run(ctx, migration, true); err != nil {
+			return err
+		}
+	}
+
+	// (4) Rollback applied migration after version, from the newest
+	for i := len(migrator.Migrations) - 1; i >= 0; i-- {
+		migration := migrator.Migrations[i]
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+			continue
+		}
+		if err := migrator.run(ctx, migration, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Function for mark version as applied and not dirty, used after database of dirty version is fixed manually
+func (migrator *Migrator) Force(ctx context.Context, version int) error {
+	// (1) Check version is available
+	migration, ok := migrator.find(version)
+	if !ok {
+		return fmt.Errorf("migration: version %d is not found", version)
+	}
+
+	// (2) Save version as applied and clean
+	if err := migrator.init(ctx); err != nil {
+		return err
+	}
+	_, err := migrator.DB.ExecContext(ctx, "INSERT INTO schema_migrations(version, name, applied_at, dirty) VALUES (?, ?, ?, FALSE) ON DUPLICATE KEY UPDATE dirty = FALSE", migration.Version, migration.Name, time.Now().UTC())
+
+	return err
+}
+
+// Function for return error when one of applied version is dirty
+func checkDirty(applied map[int]appliedVersion) error {
+	for version, applied := range applied {
+		if applied.dirty {
+			return fmt.Errorf("migration: version %d is dirty, fix the database manually then run migrate force %d", version, version)
+		}
+	}
+
+	return nil
+}
+
+// Function for find migration of version
+func (migrator *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range migrator.Migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+
+	return Migration{}, false
+}
+
+// Function for run statements of migration and save the version to schema_migrations. Statement is not run
+// in transaction, because MySQL commit DDL implicitly. Version is saved as dirty before the first statement
+// and only marked clean, or deleted when rolled back, after all statement succeed.
+func (migrator *Migrator) run(ctx context.Context, migration Migration, up bool) error {
+	// (1) Save version as dirty
+	var err error
+	if up {
+		_, err = migrator.DB.ExecContext(ctx, "INSERT INTO schema_migrations(version, name, applied_at, dirty) VALUES (?, ?, ?, TRUE)", migration.Version, migration.Name, time.Now().UTC())
+	} else {
+		_, err = migrator.DB.ExecContext(ctx, "UPDATE schema_migrations SET dirty = TRUE WHERE version = ?", migration.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	// (2) Run each statement, version stay dirty when one of statement failed
+	statements := migration.Down
+	if up {
+		statements = migration.Up
+	}
+	for _, statement := range statements {
+		if _, err := migrator.DB.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migration: version %d %s is dirty: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	// (3) Mark version as clean, or delete version that rolled back
+	if up {
+		_, err = migrator.DB.ExecContext(ctx, "UPDATE schema_migrations SET dirty = FALSE WHERE version = ?", migration.Version)
+	} else {
+		_, err = migrator.DB.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+	}
+
+	return err
+}
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS category (
+  id INT NOT NULL AUTO_INCREMENT,
+  name VARCHAR(200) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE = InnoDB;
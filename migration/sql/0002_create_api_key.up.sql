@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_key (
+  id INT NOT NULL AUTO_INCREMENT,
+  name VARCHAR(100) NOT NULL,
+  key_hash CHAR(64) NOT NULL,
+  key_prefix VARCHAR(16) NOT NULL,
+  created_at DATETIME NOT NULL,
+  last_used_at DATETIME NULL,
+  expires_at DATETIME NULL,
+  revoked BOOLEAN NOT NULL DEFAULT FALSE,
+  PRIMARY KEY (id),
+  UNIQUE KEY api_key_key_hash (key_hash)
+) ENGINE = InnoDB;
@@ -0,0 +1 @@
+ALTER TABLE api_key ADD COLUMN scopes VARCHAR(500) NOT NULL DEFAULT 'categories:read categories:write categories:delete';
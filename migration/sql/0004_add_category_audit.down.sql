@@ -0,0 +1,6 @@
+DROP INDEX category_updated_at ON category;
+ALTER TABLE category
+  DROP COLUMN created_at,
+  DROP COLUMN updated_at,
+  DROP COLUMN created_by,
+  DROP COLUMN updated_by;
@@ -0,0 +1,6 @@
+ALTER TABLE category
+  ADD COLUMN created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  ADD COLUMN updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  ADD COLUMN created_by VARCHAR(200) NOT NULL DEFAULT '',
+  ADD COLUMN updated_by VARCHAR(200) NOT NULL DEFAULT '';
+CREATE INDEX category_updated_at ON category (updated_at);
@@ -0,0 +1,2 @@
+DROP INDEX category_deleted_at ON category;
+ALTER TABLE category DROP COLUMN deleted_at;
@@ -0,0 +1,2 @@
+ALTER TABLE category ADD COLUMN deleted_at DATETIME(6) NULL;
+CREATE INDEX category_deleted_at ON category (deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE category ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP INDEX category_parent_id ON category;
+ALTER TABLE category DROP COLUMN parent_id;
@@ -0,0 +1,2 @@
+ALTER TABLE category ADD COLUMN parent_id INT NULL;
+CREATE INDEX category_parent_id ON category (parent_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS idempotency_key (
+  id INT NOT NULL AUTO_INCREMENT,
+  idempotency_key VARCHAR(255) NOT NULL,
+  principal VARCHAR(255) NOT NULL,
+  fingerprint CHAR(64) NOT NULL,
+  status_code INT NOT NULL DEFAULT 0,
+  header TEXT NULL,
+  body MEDIUMBLOB NULL,
+  created_at DATETIME NOT NULL,
+  expires_at DATETIME NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY idempotency_key_principal_key (principal, idempotency_key),
+  KEY idempotency_key_expires_at (expires_at)
+) ENGINE = InnoDB;
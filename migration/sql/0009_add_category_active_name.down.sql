@@ -0,0 +1,3 @@
+ALTER TABLE category
+  DROP INDEX category_active_name,
+  DROP COLUMN active_name;
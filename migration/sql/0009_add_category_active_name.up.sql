@@ -0,0 +1,4 @@
+-- Name of active category is unique case insensitive, name of deleted category is null so it can be used again
+ALTER TABLE category
+  ADD COLUMN active_name VARCHAR(200) AS (IF(deleted_at IS NULL, LOWER(name), NULL)) STORED,
+  ADD UNIQUE INDEX category_active_name (active_name);
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// This is a file domain or entity for table api_key
+// Key is never saved, only the hash of key
+type ApiKey struct {
+	Id         int
+	Name       string
+	KeyHash    string // SHA-256 of key in hex
+	KeyPrefix  string // The first characters of key, for identify key without show the key
+	CreatedAt  time.Time
+	LastUsedAt time.Time // Zero when key never used
+	ExpiresAt  time.Time // Zero when key never expired
+	Revoked    bool
+	Scopes     []string // Granted scope, saved separated with space
+}
+
+// Function for check whether API key can be used at time now
+func (apiKey ApiKey) Active(now time.Time) bool {
+	return !apiKey.Revoked && (apiKey.ExpiresAt.IsZero() || now.Before(apiKey.ExpiresAt))
+}
@@ -1,8 +1,22 @@
 package domain
 
+import "time"
+
 // This is a file domain or entity for table category
 // Create attribute for table category
 type Category struct {
-	Id   int
-	Name string
+	Id        int
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy string    // Name of principal that create category, empty for data before audit
+	UpdatedBy string    // Name of principal that last update category
+	DeletedAt time.Time // Zero when category is not deleted
+	Version   int       // Incremented on each update, for optimistic concurrency
+	ParentId  int       // Id of parent category, 0 for root category
+}
+
+// Function for check whether category is soft deleted
+func (category Category) Deleted() bool {
+	return !category.DeletedAt.IsZero()
 }
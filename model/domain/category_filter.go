@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Filter for find categories
+type CategoryFilter struct {
+	Query        string    // Search category by name, case insensitive
+	MatchPrefix  bool      // Search name by prefix instead of substring
+	UpdatedSince time.Time // Only data updated at or after this time, zero for no filter
+	WithDeleted  bool      // Include soft deleted data
+	ParentId     int       // Only children of this category, 0 for no filter
+	Ids          []int     // Only data with one of these id, empty for no filter
+	Names        []string  // Only data with one of these name case insensitive, empty for no filter
+	Sort         string    // Sort by field, "id", "name", "created_at" or "updated_at", default "id"
+	Desc         bool      // Sort descending
+	Limit        int       // Max data returned, 0 for no limit
+	Offset       int       // Skip data before offset, used by page pagination
+	AfterId      int       // Cursor, only data with id greater than AfterId
+	BeforeId     int       // Cursor, only data with id less than BeforeId, return the last data before cursor
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// This is a file domain or entity for table idempotency_key
+// Response of request is saved with the key, so request that retried with the same key get the same response
+type IdempotencyKey struct {
+	Id          int
+	Key         string
+	Principal   string            // Caller that send the key, the same key from other caller is other key
+	Fingerprint string            // SHA-256 of request in hex, for check the key is used for the same request
+	StatusCode  int               // 0 when request is still processed
+	Header      map[string]string // Header of response that written again with the response
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Function for check whether response of request is already saved
+func (idempotencyKey IdempotencyKey) Completed() bool {
+	return idempotencyKey.StatusCode != 0
+}
@@ -0,0 +1,19 @@
+package domain
+
+// Authenticated caller of API
+type Principal struct {
+	Name   string                 // Name of API key or subject of token
+	Method string                 // Authentication method, "api_key" or "jwt"
+	Scopes []string               // Granted scope, e.g. "categories:read"
+	Claims map[string]interface{} // Verified claims of token, nil for API key
+}
+
+// Function for check whether principal is granted the scope
+func (principal Principal) HasScope(scope string) bool {
+	for _, granted := range principal.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package domain
+
+// Scope that can be granted to API key or token
+const (
+	ScopeCategoriesRead   = "categories:read"   // List and get category
+	ScopeCategoriesWrite  = "categories:write"  // Create and update category
+	ScopeCategoriesDelete = "categories:delete" // Delete category
+	ScopeAdmin            = "admin"             // Manage API key
+)
+
+// Scope of category, default scope for API key issued at runtime
+var CategoryScopes = []string{ScopeCategoriesRead, ScopeCategoriesWrite, ScopeCategoriesDelete}
+
+// All scope, default scope for API key from configuration
+var AllScopes = append(append([]string{}, CategoryScopes...), ScopeAdmin)
@@ -0,0 +1,10 @@
+package web
+
+import "time"
+
+// Struct for request issue new API key
+type ApiKeyCreateRequest struct {
+	Name      string     `validate:"required,max=100,min=1" json:"name"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Scopes    []string   `validate:"omitempty,dive,oneof=categories:read categories:write categories:delete" json:"scopes"` // Default all scope of category, scope admin only for API key from configuration
+}
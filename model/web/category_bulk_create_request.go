@@ -0,0 +1,8 @@
+package web
+
+// Struct for request create many data in one request
+type CategoryBulkCreateRequest struct {
+	Items []CategoryCreateRequest `validate:"min=1,max=500" json:"items"`
+	// Valid item is still created when other item failed, otherwise no item is created
+	BestEffort bool `json:"-"`
+}
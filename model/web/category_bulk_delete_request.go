@@ -0,0 +1,8 @@
+package web
+
+// Struct for request delete many data in one request
+type CategoryBulkDeleteRequest struct {
+	Items []CategoryDeleteRequest `validate:"min=1,max=500" json:"items"`
+	// Valid item is still deleted when other item failed, otherwise no item is deleted
+	BestEffort bool `json:"-"`
+}
@@ -0,0 +1,19 @@
+package web
+
+// Struct for response of bulk request, result of each item has the same order as request
+type CategoryBulkResponse struct {
+	Succeeded int                        `json:"succeeded"`
+	Failed    int                        `json:"failed"`
+	Items     []CategoryBulkItemResponse `json:"items"`
+}
+
+// Result of one item in bulk request
+type CategoryBulkItemResponse struct {
+	Index  int               `json:"index"`            // Index of item in request
+	Status int               `json:"status"`           // Status code as if item is sent in its own request
+	Data   *CategoryResponse `json:"data,omitempty"`   // Only available for succeeded item
+	Error  string            `json:"error,omitempty"`  // Only available for failed item
+	Errors []FieldError      `json:"errors,omitempty"` // Error of each field when item is not valid
+	// Id of existing category that conflict with item, e.g. name that already used
+	ExistingId int `json:"existing_id,omitempty"`
+}
@@ -0,0 +1,8 @@
+package web
+
+// Struct for request update many data in one request, id of category is in each item
+type CategoryBulkUpdateRequest struct {
+	Items []CategoryUpdateRequest `validate:"min=1,max=500" json:"items"`
+	// Valid item is still updated when other item failed, otherwise no item is updated
+	BestEffort bool `json:"-"`
+}
@@ -2,5 +2,6 @@ package web
 
 // Struct for request create new data
 type CategoryCreateRequest struct {
-	Name string `validate:"required,max=200,min=1" json:"name"`
+	Name     string `validate:"required,max=200,min=1" json:"name"`
+	ParentId int    `validate:"min=0" json:"parent_id"` // 0 for root category
 }
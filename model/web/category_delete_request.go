@@ -0,0 +1,12 @@
+package web
+
+// Struct for request delete data
+type CategoryDeleteRequest struct {
+	Id int `validate:"required" json:"id"`
+	// Behavior when category has children, reject, cascade or reparent.
+	// Empty for default behavior from configuration.
+	Children string `validate:"omitempty,oneof=reject cascade reparent" json:"children"`
+	// Version from header If-Match, category is only deleted when version is one of them.
+	// Empty for delete without check.
+	IfMatch []int `json:"-"`
+}
@@ -0,0 +1,8 @@
+package web
+
+// Struct for event of category change in event stream
+type CategoryEvent struct {
+	Id       int               `json:"id"`                 // Increasing id of event, for resume with header Last-Event-ID
+	Type     string            `json:"type"`               // created, updated, deleted, or reset when event is missed
+	Category *CategoryResponse `json:"category,omitempty"` // Category after changed, null for reset
+}
@@ -0,0 +1,19 @@
+package web
+
+// Struct for request import category from csv or ndjson file
+type CategoryImportRequest struct {
+	Rows []CategoryImportRow `validate:"min=1" json:"rows"`
+	// Only check each row without write category
+	DryRun bool `json:"-"`
+	// Valid row is still written when other row failed, otherwise no row is written
+	BestEffort bool `json:"-"`
+}
+
+// One row of imported file, row with id update the category, otherwise create new category
+type CategoryImportRow struct {
+	Line     int    `json:"-"` // Line number in file, start from 1
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentId int    `json:"parent_id"` // 0 for root category
+	Error    error  `json:"-"`         // Error when row can not be read, row is failed without validated
+}
@@ -0,0 +1,21 @@
+package web
+
+// Struct for response of import category
+type CategoryImportResponse struct {
+	DryRun  bool                        `json:"dry_run"`
+	Applied bool                        `json:"applied"` // False when dry run or one of row failed in atomic mode
+	Created int                         `json:"created"`
+	Updated int                         `json:"updated"`
+	Failed  int                         `json:"failed"`
+	Errors  []CategoryImportRowResponse `json:"errors"` // Only failed row
+}
+
+// Error of one row in imported file
+type CategoryImportRowResponse struct {
+	Line   int          `json:"line"`             // Line number in file, start from 1
+	Status int          `json:"status"`           // Status code as if row is sent in its own request
+	Error  string       `json:"error"`            // Message of error
+	Errors []FieldError `json:"errors,omitempty"` // Error of each field when row is not valid
+	// Id of existing category that conflict with row, e.g. name that already used
+	ExistingId int `json:"existing_id,omitempty"`
+}
@@ -0,0 +1,17 @@
+package web
+
+import "time"
+
+// Struct for request list categories, use page/size or cursor after/before
+type CategoryListRequest struct {
+	Page           int        `validate:"min=0" json:"page"`
+	Size           int        `validate:"min=0" json:"size"`
+	After          int        `validate:"min=0" json:"after"`
+	Before         int        `validate:"min=0" json:"before"`
+	Q              string     `validate:"max=200" json:"q"`
+	Match          string     `validate:"omitempty,oneof=contains prefix" json:"match"`
+	UpdatedSince   *time.Time `json:"updated_since"`
+	IncludeDeleted bool       `json:"include_deleted"`
+	Sort           string     `validate:"omitempty,oneof=id name created_at updated_at" json:"sort"`
+	Order          string     `validate:"omitempty,oneof=asc desc" json:"order"`
+}
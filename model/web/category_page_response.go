@@ -0,0 +1,13 @@
+package web
+
+// Struct for response list categories with pagination info
+type CategoryPageResponse struct {
+	Categories []CategoryResponse
+	Page       int  // Current page, 0 for cursor pagination
+	Size       int  // Page size after limited by server
+	Total      int  // Total all categories
+	HasNext    bool // Whether next page is available
+	HasPrev    bool // Whether previous page is available
+	NextAfter  int  // Cursor for next page
+	PrevBefore int  // Cursor for previous page
+}
@@ -0,0 +1,8 @@
+package web
+
+import "time"
+
+// Struct for request permanently delete categories that soft deleted before retention window
+type CategoryPurgeRequest struct {
+	Retention *time.Duration `validate:"omitempty,min=0" json:"retention"` // Default service.DefaultPurgeRetention
+}
@@ -0,0 +1,8 @@
+package web
+
+import "time"
+
+type CategoryPurgeResponse struct {
+	Purged        int       `json:"purged"`         // Total category permanently deleted
+	DeletedBefore time.Time `json:"deleted_before"` // Only category deleted before this time is purged
+}
@@ -1,6 +1,15 @@
 package web
 
+import "time"
+
 type CategoryResponse struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	Id        int        `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	CreatedBy string     `json:"created_by"`
+	UpdatedBy string     `json:"updated_by"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // Only available for deleted category
+	Version   int        `json:"version"`              // Same as value of header ETag
+	ParentId  *int       `json:"parent_id"`            // Null for root category
 }
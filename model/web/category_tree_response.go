@@ -0,0 +1,7 @@
+package web
+
+// Struct for response category with all children as nested tree
+type CategoryTreeResponse struct {
+	CategoryResponse
+	Children []CategoryTreeResponse `json:"children"`
+}
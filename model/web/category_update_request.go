@@ -1,6 +1,10 @@
 package web
 
 type CategoryUpdateRequest struct {
-	Id   int    `validate:"required" json:"id"`
-	Name string `validate:"required,max=200,min=1" json:"name"`
+	Id       int    `validate:"required" json:"id"`
+	Name     string `validate:"required,max=200,min=1" json:"name"`
+	ParentId int    `validate:"min=0" json:"parent_id"` // 0 for root category
+	// Version from header If-Match, category is only updated when version is one of them.
+	// Empty for update without check.
+	IfMatch []int `json:"-"`
 }
@@ -0,0 +1,7 @@
+package web
+
+// Data of error response when request conflict with existing data, e.g. name that already used
+type ConflictResponse struct {
+	Message    string `json:"message"`
+	ExistingId int    `json:"existing_id"` // Id of the existing data
+}
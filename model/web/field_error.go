@@ -0,0 +1,9 @@
+package web
+
+// Error of one field in request
+type FieldError struct {
+	Field   string `json:"field"`   // Name of field in request, e.g. "name"
+	Rule    string `json:"rule"`    // Validation rule, e.g. "required" or "max"
+	Param   string `json:"param"`   // Parameter of rule, e.g. "200" for max=200
+	Message string `json:"message"` // Message that can be shown to user
+}
@@ -0,0 +1,9 @@
+package web
+
+type Paging struct {
+	Page  int    `json:"page,omitempty"`
+	Size  int    `json:"size"`
+	Total int    `json:"total"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
@@ -0,0 +1,13 @@
+package web
+
+// Error response with format RFC 7807, media type application/problem+json
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	// Extension member, id of existing data that conflict with request
+	ExistingId int `json:"existing_id,omitempty"`
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Contract for repository api key
+type ApiKeyRepository interface {
+	// Contract function Save for insert data
+	Save(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error)
+	// Contract function Update for update data
+	Update(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error)
+	// Contract function FindById for find data based on id, return exception.NotFoundError if data is not available
+	FindById(ctx context.Context, tx Tx, apiKeyId int) (domain.ApiKey, error)
+	// Contract function FindByHash for find data based on hash of key, return exception.NotFoundError if data is not available
+	FindByHash(ctx context.Context, tx Tx, keyHash string) (domain.ApiKey, error)
+	// Contract function FindAll for find all data, ordered by id
+	FindAll(ctx context.Context, tx Tx) ([]domain.ApiKey, error)
+}
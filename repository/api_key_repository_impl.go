@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+type ApiKeyRepositoryImpl struct {
+}
+
+func NewApiKeyRepository() ApiKeyRepository {
+	return &ApiKeyRepositoryImpl{}
+}
+
+// Column of table api_key, same order with function scanApiKey
+const apiKeyColumns = "id, name, key_hash, key_prefix, created_at, last_used_at, expires_at, revoked, scopes"
+
+// Function for convert zero time to null
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// Function for scan one row of table api_key
+func scanApiKey(scanner interface{ Scan(...interface{}) error }) (domain.ApiKey, error) {
+	apiKey := domain.ApiKey{}
+	var createdAt, lastUsedAt, expiresAt helper.NullTime
+	var scopes string
+
+	err := scanner.Scan(&apiKey.Id, &apiKey.Name, &apiKey.KeyHash, &apiKey.KeyPrefix, &createdAt, &lastUsedAt, &expiresAt, &apiKey.Revoked, &scopes)
+	apiKey.CreatedAt = createdAt.Time
+	apiKey.LastUsedAt = lastUsedAt.Time
+	apiKey.ExpiresAt = expiresAt.Time
+	apiKey.Scopes = strings.Fields(scopes)
+
+	return apiKey, err
+}
+
+// Function Save with follow the contract api key repository
+func (repository *ApiKeyRepositoryImpl) Save(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error) {
+	// (1) Create sql query
+	SQL := "insert into api_key(name, key_hash, key_prefix, created_at, last_used_at, expires_at, revoked, scopes) values (?, ?, ?, ?, ?, ?, ?, ?)"
+
+	// (2) Create context
+	result, err := sqlTx(tx).ExecContext(ctx, SQL, apiKey.Name, apiKey.KeyHash, apiKey.KeyPrefix, apiKey.CreatedAt.UTC(), nullTime(apiKey.LastUsedAt), nullTime(apiKey.ExpiresAt), apiKey.Revoked, strings.Join(apiKey.Scopes, " "))
+	if err != nil {
+		return apiKey, exception.NewInternalError(err)
+	}
+
+	// (3) If success, get last insert id
+	id, err := result.LastInsertId()
+	if err != nil {
+		return apiKey, exception.NewInternalError(err)
+	}
+	apiKey.Id = int(id)
+
+	return apiKey, nil
+}
+
+// Function Update with follow the contract api key repository
+func (repository *ApiKeyRepositoryImpl) Update(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error) {
+	// (1) Create sql query
+	SQL := "update api_key set name = ?, key_hash = ?, key_prefix = ?, last_used_at = ?, expires_at = ?, revoked = ?, scopes = ? where id = ?"
+
+	// (2) Create context
+	_, err := sqlTx(tx).ExecContext(ctx, SQL, apiKey.Name, apiKey.KeyHash, apiKey.KeyPrefix, nullTime(apiKey.LastUsedAt), nullTime(apiKey.ExpiresAt), apiKey.Revoked, strings.Join(apiKey.Scopes, " "), apiKey.Id)
+	if err != nil {
+		return apiKey, exception.NewInternalError(err)
+	}
+
+	return apiKey, nil
+}
+
+// Function for find one api key with where clause
+func (repository *ApiKeyRepositoryImpl) findOne(ctx context.Context, tx Tx, where string, arg interface{}) (domain.ApiKey, error) {
+	SQL := "select " + apiKeyColumns + " from api_key where " + where
+
+	apiKey, err := scanApiKey(sqlTx(tx).QueryRowContext(ctx, SQL, arg))
+	if err == sql.ErrNoRows {
+		return apiKey, exception.NewNotFoundError("api key is not found")
+	}
+	if err != nil {
+		return apiKey, exception.NewInternalError(err)
+	}
+
+	return apiKey, nil
+}
+
+// Function Find data by id with follow the contract api key repository
+func (repository *ApiKeyRepositoryImpl) FindById(ctx context.Context, tx Tx, apiKeyId int) (domain.ApiKey, error) {
+	return repository.findOne(ctx, tx, "id = ?", apiKeyId)
+}
+
+// Function Find data by hash of key with follow the contract api key repository
+func (repository *ApiKeyRepositoryImpl) FindByHash(ctx context.Context, tx Tx, keyHash string) (domain.ApiKey, error) {
+	return repository.findOne(ctx, tx, "key_hash = ?", keyHash)
+}
+
+// Function Find all data with follow the contract api key repository
+func (repository *ApiKeyRepositoryImpl) FindAll(ctx context.Context, tx Tx) ([]domain.ApiKey, error) {
+	// (1) Create query context
+	rows, err := sqlTx(tx).QueryContext(ctx, "select "+apiKeyColumns+" from api_key order by id")
+	if err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+
+	// (2) Close rows after use
+	defer rows.Close()
+
+	// (3) Scan all data
+	var apiKeys []domain.ApiKey
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			return nil, exception.NewInternalError(err)
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+
+	return apiKeys, nil
+}
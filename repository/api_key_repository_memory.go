@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Api key repository with in memory storage
+type ApiKeyRepositoryMemory struct {
+	DB *MemoryDB // Use in memory storage
+}
+
+func NewApiKeyRepositoryMemory(db *MemoryDB) ApiKeyRepository {
+	return &ApiKeyRepositoryMemory{DB: db}
+}
+
+// Function for get table api_key in transaction
+func (repository *ApiKeyRepositoryMemory) table(tx Tx) *memoryTable {
+	return memoryTableOf(repository.DB, tx, "api_key")
+}
+
+// Function Save with follow the contract api key repository
+func (repository *ApiKeyRepositoryMemory) Save(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error) {
+	table := repository.table(tx)
+
+	apiKey.Id = table.nextId()
+	table.rows[apiKey.Id] = apiKey
+
+	return apiKey, nil
+}
+
+// Function Update with follow the contract api key repository
+func (repository *ApiKeyRepositoryMemory) Update(ctx context.Context, tx Tx, apiKey domain.ApiKey) (domain.ApiKey, error) {
+	table := repository.table(tx)
+
+	if _, ok := table.rows[apiKey.Id]; ok {
+		table.rows[apiKey.Id] = apiKey
+	}
+
+	return apiKey, nil
+}
+
+// Function Find data by id with follow the contract api key repository
+func (repository *ApiKeyRepositoryMemory) FindById(ctx context.Context, tx Tx, apiKeyId int) (domain.ApiKey, error) {
+	row, ok := repository.table(tx).rows[apiKeyId]
+	if !ok {
+		return domain.ApiKey{}, exception.NewNotFoundError("api key is not found")
+	}
+
+	return row.(domain.ApiKey), nil
+}
+
+// Function Find data by hash of key with follow the contract api key repository
+func (repository *ApiKeyRepositoryMemory) FindByHash(ctx context.Context, tx Tx, keyHash string) (domain.ApiKey, error) {
+	for _, row := range repository.table(tx).rows {
+		if apiKey := row.(domain.ApiKey); apiKey.KeyHash == keyHash {
+			return apiKey, nil
+		}
+	}
+
+	return domain.ApiKey{}, exception.NewNotFoundError("api key is not found")
+}
+
+// Function Find all data with follow the contract api key repository
+func (repository *ApiKeyRepositoryMemory) FindAll(ctx context.Context, tx Tx) ([]domain.ApiKey, error) {
+	var apiKeys []domain.ApiKey
+	for _, row := range repository.table(tx).rows {
+		apiKeys = append(apiKeys, row.(domain.ApiKey))
+	}
+
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].Id < apiKeys[j].Id
+	})
+
+	return apiKeys, nil
+}
@@ -2,7 +2,7 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"time"
 
 	"github.com/jabutech/go-crud-restful-api/model/domain"
 )
@@ -10,13 +10,38 @@ import (
 // Contract for repository category
 type CategoryRepository interface {
 	// Contract function Save for insert data
-	Save(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category
-	// Contract function Update for update data
-	Update(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category
-	// Contract function Delete for delete data
-	Delete(ctx context.Context, tx *sql.Tx, category domain.Category) string
-	// Contract function FindId for find data based on id
-	FindById(ctx context.Context, tx *sql.Tx, categoryId int) (domain.Category, error)
-	// Contract function FindAll for find all data
-	FindAll(ctx context.Context, tx *sql.Tx) []domain.Category
+	Save(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error)
+	// Contract function SaveAll for insert many data in one transaction, return data with id in the same order
+	SaveAll(ctx context.Context, tx Tx, categories []domain.Category) ([]domain.Category, error)
+	// Contract function Update for update data and increment version, return exception.PreconditionFailedError
+	// if version of data is not same with version of category anymore
+	Update(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error)
+	// Contract function UpdateAll for update many data in one query include DeletedAt, version is checked
+	// and incremented same as Update
+	UpdateAll(ctx context.Context, tx Tx, categories []domain.Category) ([]domain.Category, error)
+	// Contract function Delete for soft delete data, save DeletedAt, UpdatedAt and UpdatedBy of category.
+	// Version is checked and incremented same as Update
+	Delete(ctx context.Context, tx Tx, category domain.Category) error
+	// Contract function FindId for find data based on id, return exception.NotFoundError if data is not available or deleted
+	FindById(ctx context.Context, tx Tx, categoryId int) (domain.Category, error)
+	// Contract function FindByIdWithDeleted for find data based on id include soft deleted data
+	FindByIdWithDeleted(ctx context.Context, tx Tx, categoryId int) (domain.Category, error)
+	// Contract function FindByName for find data that not deleted based on name case insensitive,
+	// return exception.NotFoundError if data is not available
+	FindByName(ctx context.Context, tx Tx, name string) (domain.Category, error)
+	// Contract function FindAll for find all data match with filter, ordered by id
+	FindAll(ctx context.Context, tx Tx, filter domain.CategoryFilter) ([]domain.Category, error)
+	// Contract function FindAncestors for find data that not deleted with one of the id and all of its ancestor
+	// that not deleted, ordered by id
+	FindAncestors(ctx context.Context, tx Tx, categoryIds []int) ([]domain.Category, error)
+	// Contract function FindDescendants for find all descendant that not deleted of data with one of the id,
+	// without the data itself, ordered by id
+	FindDescendants(ctx context.Context, tx Tx, categoryIds []int) ([]domain.Category, error)
+	// Contract function FindEach for call fn with each data match with filter in the same order as FindAll,
+	// without load all data to memory. Stop and return the error when fn return error
+	FindEach(ctx context.Context, tx Tx, filter domain.CategoryFilter, fn func(category domain.Category) error) error
+	// Contract function Count for count all data match with filter, ignore limit, offset and cursor
+	Count(ctx context.Context, tx Tx, filter domain.CategoryFilter) (int, error)
+	// Contract function Purge for permanently delete data soft deleted before the time, return total deleted data
+	Purge(ctx context.Context, tx Tx, deletedBefore time.Time) (int, error)
 }
@@ -3,8 +3,11 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/jabutech/go-crud-restful-api/exception"
 	"github.com/jabutech/go-crud-restful-api/helper"
 	"github.com/jabutech/go-crud-restful-api/model/domain"
 )
@@ -16,123 +19,570 @@ func NewCategoriRepository() CategoryRepository {
 	return &CategoryRepositoryImpl{}
 }
 
+// Column of table category, same order with function scanCategory
+const categoryColumns = "id, name, created_at, updated_at, created_by, updated_by, deleted_at, version, parent_id"
+
+// Function for convert id 0 to null
+func nullId(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// Function for scan one row of table category
+func scanCategory(scanner interface{ Scan(...interface{}) error }) (domain.Category, error) {
+	category := domain.Category{}
+	var createdAt, updatedAt, deletedAt helper.NullTime
+	var parentId sql.NullInt64
+
+	err := scanner.Scan(&category.Id, &category.Name, &createdAt, &updatedAt, &category.CreatedBy, &category.UpdatedBy, &deletedAt, &category.Version, &parentId)
+	category.CreatedAt = createdAt.Time
+	category.UpdatedAt = updatedAt.Time
+	category.DeletedAt = deletedAt.Time
+	category.ParentId = int(parentId.Int64)
+
+	return category, err
+}
+
 // Function Save with follow the contract category repository
-func (repository *CategoryRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category {
+func (repository *CategoryRepositoryImpl) Save(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error) {
 	// (1) Create sql query
-	SQL := "insert into category(name) values (?)"
+	SQL := "insert into category(name, created_at, updated_at, created_by, updated_by, version, parent_id) values (?, ?, ?, ?, ?, 1, ?)"
 
 	// (2) Create context
-	result, err := tx.ExecContext(ctx, SQL, category.Name)
+	result, err := sqlTx(tx).ExecContext(ctx, SQL, category.Name, category.CreatedAt.UTC(), category.UpdatedAt.UTC(), category.CreatedBy, category.UpdatedBy, nullId(category.ParentId))
 
-	// (3) If error handle error with helper error
-	helper.PanicErr(err)
+	// (3) If error return internal error, name that already used is rejected by unique index
+	if isDuplicateEntry(err) {
+		return category, duplicateNameError()
+	}
+	if err != nil {
+		return category, exception.NewInternalError(err)
+	}
 
 	// (4) If success, get last insert id
 	id, err := result.LastInsertId()
 
 	// (5) Handle if error
-	helper.PanicErr(err)
+	if err != nil {
+		return category, exception.NewInternalError(err)
+	}
 
 	// (6) Set last insert id to category id and convert from type int64 to int
 	category.Id = int(id)
+	category.Version = 1
 
 	// (7) Return category
-	return category
+	return category, nil
+}
+
+// Function SaveAll with follow the contract category repository
+func (repository *CategoryRepositoryImpl) SaveAll(ctx context.Context, tx Tx, categories []domain.Category) ([]domain.Category, error) {
+	if len(categories) == 0 {
+		return categories, nil
+	}
+
+	// (1) Create sql query, all category is inserted with one statement
+	values := make([]string, len(categories))
+	var args []interface{}
+	for i, category := range categories {
+		values[i] = "(?, ?, ?, ?, ?, 1, ?)"
+		args = append(args, category.Name, category.CreatedAt.UTC(), category.UpdatedAt.UTC(), category.CreatedBy, category.UpdatedBy, nullId(category.ParentId))
+	}
+	SQL := "insert into category(name, created_at, updated_at, created_by, updated_by, version, parent_id) values " + strings.Join(values, ", ")
+
+	// (2) Create context, name that already used is rejected by unique index
+	_, err := sqlTx(tx).ExecContext(ctx, SQL, args...)
+	if isDuplicateEntry(err) {
+		return categories, duplicateNameError()
+	}
+	if err != nil {
+		return categories, exception.NewInternalError(err)
+	}
+
+	// (3) Get id of each category by its name in the same transaction. Id of multi row insert is not always
+	// consecutive, e.g. with innodb_autoinc_lock_mode 2, so it is not calculated from last insert id.
+	// Name of active category is unique, so each name has only one id.
+	names := make([]interface{}, len(categories))
+	for i, category := range categories {
+		names[i] = category.Name
+	}
+	SQL = "select id, name from category where active_name in (" + strings.TrimSuffix(strings.Repeat("lower(?), ", len(names)), ", ") + ")"
+	rows, err := sqlTx(tx).QueryContext(ctx, SQL, names...)
+	if err != nil {
+		return categories, exception.NewInternalError(err)
+	}
+	defer rows.Close()
+
+	ids := map[string]int{}
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return categories, exception.NewInternalError(err)
+		}
+		ids[name] = id
+	}
+	if err := rows.Err(); err != nil {
+		return categories, exception.NewInternalError(err)
+	}
+
+	// (4) Set id to each category
+	saved := make([]domain.Category, len(categories))
+	for i, category := range categories {
+		id, ok := ids[category.Name]
+		if !ok {
+			return categories, exception.NewInternalError(fmt.Errorf("id of category %q is not found after insert", category.Name))
+		}
+		category.Id = id
+		category.Version = 1
+		saved[i] = category
+	}
+
+	return saved, nil
 }
 
 // Function Update with follow the contract category repository
-func (repository *CategoryRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category {
+func (repository *CategoryRepositoryImpl) Update(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error) {
 	// (1) Create sql query
-	SQL := "update category set name = ? where id = ?"
+	SQL := "update category set name = ?, updated_at = ?, updated_by = ?, deleted_at = ?, parent_id = ?, version = version + 1 where id = ? and version = ?"
 
 	// (2) Create context
-	_, err := tx.ExecContext(ctx, SQL, category.Name, category.Id)
+	result, err := sqlTx(tx).ExecContext(ctx, SQL, category.Name, category.UpdatedAt.UTC(), category.UpdatedBy, nullTime(category.DeletedAt), nullId(category.ParentId), category.Id, category.Version)
 
-	// (3) If error, handle with helper error
-	helper.PanicErr(err)
+	// (3) If error return internal error, name that already used is rejected by unique index
+	if isDuplicateEntry(err) {
+		return category, duplicateNameError()
+	}
+	if err != nil {
+		return category, exception.NewInternalError(err)
+	}
+
+	// (4) Return error when category updated by other transaction
+	if err := checkVersionUpdated(result); err != nil {
+		return category, err
+	}
 
-	// (4) If success, return category
-	return category
+	// (5) If success, return category with the next version
+	category.Version++
+	return category, nil
+}
+
+// Function UpdateAll with follow the contract category repository
+func (repository *CategoryRepositoryImpl) UpdateAll(ctx context.Context, tx Tx, categories []domain.Category) ([]domain.Category, error) {
+	if len(categories) == 0 {
+		return categories, nil
+	}
+
+	// (1) Create sql query, value of each category is chosen by id
+	columns := []struct {
+		name  string
+		value func(category domain.Category) interface{}
+	}{
+		{"name", func(category domain.Category) interface{} { return category.Name }},
+		{"updated_at", func(category domain.Category) interface{} { return category.UpdatedAt.UTC() }},
+		{"updated_by", func(category domain.Category) interface{} { return category.UpdatedBy }},
+		{"deleted_at", func(category domain.Category) interface{} { return nullTime(category.DeletedAt) }},
+		{"parent_id", func(category domain.Category) interface{} { return nullId(category.ParentId) }},
+	}
+
+	var sets, conditions []string
+	var args, conditionArgs []interface{}
+	for _, column := range columns {
+		set := column.name + " = case id"
+		for _, category := range categories {
+			set += " when ? then ?"
+			args = append(args, category.Id, column.value(category))
+		}
+		sets = append(sets, set+" end")
+	}
+	for _, category := range categories {
+		conditions = append(conditions, "(id = ? and version = ?)")
+		conditionArgs = append(conditionArgs, category.Id, category.Version)
+	}
+	SQL := "update category set " + strings.Join(sets, ", ") + ", version = version + 1 where " + strings.Join(conditions, " or ")
+
+	// (2) Create context
+	result, err := sqlTx(tx).ExecContext(ctx, SQL, append(args, conditionArgs...)...)
+	if isDuplicateEntry(err) {
+		return categories, duplicateNameError()
+	}
+	if err != nil {
+		return categories, exception.NewInternalError(err)
+	}
+
+	// (3) Return error when one of category updated by other transaction
+	total, err := result.RowsAffected()
+	if err != nil {
+		return categories, exception.NewInternalError(err)
+	}
+	if int(total) != len(categories) {
+		return categories, exception.NewPreconditionFailedError("category has been modified, get the latest version and try again")
+	}
+
+	// (4) If success, return categories with the next version
+	updated := make([]domain.Category, len(categories))
+	for i, category := range categories {
+		category.Version++
+		updated[i] = category
+	}
+
+	return updated, nil
 }
 
 // Function Delete with follow the contract category repository
-func (repository *CategoryRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, category domain.Category) string {
+func (repository *CategoryRepositoryImpl) Delete(ctx context.Context, tx Tx, category domain.Category) error {
 	// (1) Create sql query
-	SQL := "delete from category where id = ?"
+	SQL := "update category set deleted_at = ?, updated_at = ?, updated_by = ?, version = version + 1 where id = ? and version = ?"
 
 	// (2) Create context
-	_, err := tx.ExecContext(ctx, SQL, category.Id)
+	result, err := sqlTx(tx).ExecContext(ctx, SQL, category.DeletedAt.UTC(), category.UpdatedAt.UTC(), category.UpdatedBy, category.Id, category.Version)
+
+	// (3) If error return internal error
+	if err != nil {
+		return exception.NewInternalError(err)
+	}
+
+	// (4) Return error when category updated by other transaction, otherwise nil
+	return checkVersionUpdated(result)
+}
+
+// Function for create error of name that already used by other active category. Service check name
+// before write for message with id of the category, this error is returned when the unique index
+// reject the write, e.g. category with the same name is created by other transaction at the same time.
+func duplicateNameError() error {
+	return exception.NewConflictError("category name is already used by other category")
+}
 
-	// (3) If error handle with helper error
-	helper.PanicErr(err)
+// Function for check whether update with version found the category, category with other version
+// is already updated by other transaction
+func checkVersionUpdated(result sql.Result) error {
+	total, err := result.RowsAffected()
+	if err != nil {
+		return exception.NewInternalError(err)
+	}
 
-	// (4) Return info success deleted
-	return "Delete success."
+	if total == 0 {
+		return exception.NewPreconditionFailedError("category has been modified, get the latest version and try again")
+	}
+
+	return nil
 }
 
 // Function Find data by id with follow the contract category repository
-func (repository *CategoryRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, categoryId int) (domain.Category, error) {
+func (repository *CategoryRepositoryImpl) FindById(ctx context.Context, tx Tx, categoryId int) (domain.Category, error) {
+	return repository.findOne(ctx, tx, "id = ? and deleted_at is null", categoryId)
+}
+
+// Function Find data by id include deleted data with follow the contract category repository
+func (repository *CategoryRepositoryImpl) FindByIdWithDeleted(ctx context.Context, tx Tx, categoryId int) (domain.Category, error) {
+	return repository.findOne(ctx, tx, "id = ?", categoryId)
+}
+
+// Function Find data by name with follow the contract category repository
+func (repository *CategoryRepositoryImpl) FindByName(ctx context.Context, tx Tx, name string) (domain.Category, error) {
+	// Use column active_name, so query use unique index of name
+	return repository.findOne(ctx, tx, "active_name = lower(?)", name)
+}
+
+// Function for find one data match with condition, return error not found if data is not available
+func (repository *CategoryRepositoryImpl) findOne(ctx context.Context, tx Tx, condition string, args ...interface{}) (domain.Category, error) {
 	// (1) Create sql query
-	SQL := "select id, name from category where id = ?"
+	SQL := "select " + categoryColumns + " from category where " + condition + " limit 1"
 
 	// (2) Create query context
-	rows, err := tx.QueryContext(ctx, SQL, categoryId)
+	rows, err := sqlTx(tx).QueryContext(ctx, SQL, args...)
 
-	// (3) If error, handle with helper error
-	helper.PanicErr(err)
+	// (3) If error return internal error
+	if err != nil {
+		return domain.Category{}, exception.NewInternalError(err)
+	}
 
 	// (4) Close rows after use
 	defer rows.Close()
 
-	// (5) Create variable category with value struct domain category
-	category := domain.Category{}
-
-	// (6) If category is available
+	// (5) If category is available
 	if rows.Next() {
 		// (1) Get data category
-		err := rows.Scan(&category.Id, &category.Name)
+		category, err := scanCategory(rows)
 
-		// (2) If error, handle with helper error
-		helper.PanicErr(err)
+		// (2) If error return internal error
+		if err != nil {
+			return category, exception.NewInternalError(err)
+		}
 
 		// (3) If no, return category with error nil
 		return category, nil
 	} else {
-		// If category is empty, return category and send info error
-		return category, errors.New("category is not found")
+		// If category is empty, return empty category and send error not found
+		return domain.Category{}, exception.NewNotFoundError("category is not found")
 	}
 }
 
-// Function Find all data with follow the contract category repository
-func (repository *CategoryRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []domain.Category {
-	// (1) Create sql query
-	SQL := "select id, name from category"
+// Column allowed for sort, key is sort field from filter
+var categorySortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
 
-	// (2) Create query context
-	rows, err := tx.QueryContext(ctx, SQL)
+// Replacer for escape wildcard character in like pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
 
-	// (3) If error, handle with helper error
-	helper.PanicErr(err)
+// Function for create sql order by clause from filter, only use column from whitelist
+func categoryOrderBy(filter domain.CategoryFilter) string {
+	column, ok := categorySortColumns[filter.Sort]
+	if !ok {
+		column = "id"
+	}
 
-	// (4) Close rows after use
-	defer rows.Close()
+	direction := "asc"
+	if filter.Desc {
+		direction = "desc"
+	}
+
+	// Use id as second order, so the order is same for name with same value
+	if column == "id" {
+		return " order by id " + direction
+	}
+	return " order by " + column + " " + direction + ", id " + direction
+}
+
+// Function for create placeholder of n value separated by comma
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// Function for create sql where clause from filter
+func categoryWhere(filter domain.CategoryFilter, withCursor bool) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if !filter.WithDeleted {
+		conditions = append(conditions, "deleted_at is null")
+	}
+
+	if filter.ParentId > 0 {
+		conditions = append(conditions, "parent_id = ?")
+		args = append(args, filter.ParentId)
+	}
+
+	if len(filter.Ids) > 0 {
+		conditions = append(conditions, "id in ("+placeholders(len(filter.Ids))+")")
+		for _, id := range filter.Ids {
+			args = append(args, id)
+		}
+	}
+
+	if len(filter.Names) > 0 {
+		// Active data use column active_name, so query use unique index of name
+		column := "active_name"
+		if filter.WithDeleted {
+			column = "lower(name)"
+		}
+		conditions = append(conditions, column+" in ("+placeholders(len(filter.Names))+")")
+		for _, name := range filter.Names {
+			args = append(args, strings.ToLower(name))
+		}
+	}
+
+	if filter.Query != "" {
+		// Escape wildcard character, so query is searched as plain text
+		pattern := likeEscaper.Replace(filter.Query) + "%"
+		if !filter.MatchPrefix {
+			pattern = "%" + pattern
+		}
+		conditions = append(conditions, "name like ?")
+		args = append(args, pattern)
+	}
 
-	// (5) Create var category with value slice domain category
+	if !filter.UpdatedSince.IsZero() {
+		conditions = append(conditions, "updated_at >= ?")
+		args = append(args, filter.UpdatedSince.UTC())
+	}
+
+	if withCursor && filter.AfterId > 0 {
+		conditions = append(conditions, "id > ?")
+		args = append(args, filter.AfterId)
+	}
+	if withCursor && filter.BeforeId > 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, filter.BeforeId)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " where " + strings.Join(conditions, " and "), args
+}
+
+// Function Find all data with follow the contract category repository
+func (repository *CategoryRepositoryImpl) FindAll(ctx context.Context, tx Tx, filter domain.CategoryFilter) ([]domain.Category, error) {
+	// (1) Create var category with value slice domain category
 	var categories []domain.Category
 
+	// (2) Insert all data to var categories
+	err := repository.FindEach(ctx, tx, filter, func(category domain.Category) error {
+		categories = append(categories, category)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// (3) return all data category
+	return categories, nil
+}
+
+// Function FindEach with follow the contract category repository
+func (repository *CategoryRepositoryImpl) FindEach(ctx context.Context, tx Tx, filter domain.CategoryFilter, fn func(category domain.Category) error) error {
+	// (1) Create sql query with filter
+	where, args := categoryWhere(filter, true)
+	SQL := "select " + categoryColumns + " from category" + where
+
+	// (2) For cursor before, take the last data before cursor then order again by id
+	if filter.BeforeId > 0 {
+		SQL += " order by id desc"
+		if filter.Limit > 0 {
+			SQL += " limit ?"
+			args = append(args, filter.Limit)
+		}
+		SQL = "select " + categoryColumns + " from (" + SQL + ") category order by id"
+	} else {
+		SQL += categoryOrderBy(filter)
+		if filter.Limit > 0 {
+			SQL += " limit ? offset ?"
+			args = append(args, filter.Limit, filter.Offset)
+		}
+	}
+
+	// (3) Create query context
+	rows, err := sqlTx(tx).QueryContext(ctx, SQL, args...)
+
+	// (4) If error return internal error
+	if err != nil {
+		return exception.NewInternalError(err)
+	}
+
+	// (5) Close rows after use
+	defer rows.Close()
+
 	// (6) If category is available
 	for rows.Next() {
-		// (1) Create var category with strucy domain category
-		category := domain.Category{}
-		err := rows.Scan(&category.Id, &category.Name)
+		// (1) Scan data to category
+		category, err := scanCategory(rows)
+
+		// (2) If error return internal error
+		if err != nil {
+			return exception.NewInternalError(err)
+		}
+
+		// (3) If no error, send category to fn, one row at a time
+		if err := fn(category); err != nil {
+			return err
+		}
+	}
+
+	// (7) Check error while iterate rows
+	if err := rows.Err(); err != nil {
+		return exception.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// Function FindAncestors with follow the contract category repository
+func (repository *CategoryRepositoryImpl) FindAncestors(ctx context.Context, tx Tx, categoryIds []int) ([]domain.Category, error) {
+	// Union without all remove the same row, so invalid data with cycle not cause infinite recursion
+	SQL := "with recursive lineage (id, parent_id) as (" +
+		"select id, parent_id from category where id in (" + placeholders(len(categoryIds)) + ") and deleted_at is null " +
+		"union select category.id, category.parent_id from category join lineage on category.id = lineage.parent_id where category.deleted_at is null" +
+		") select " + categoryColumns + " from category where id in (select id from lineage) order by id"
 
-		// (2) If error, handle with helper error
-		helper.PanicErr(err)
+	return repository.query(ctx, tx, categoryIds, SQL)
+}
+
+// Function FindDescendants with follow the contract category repository
+func (repository *CategoryRepositoryImpl) FindDescendants(ctx context.Context, tx Tx, categoryIds []int) ([]domain.Category, error) {
+	// Union without all remove the same row, so invalid data with cycle not cause infinite recursion
+	SQL := "with recursive descendant (id) as (" +
+		"select id from category where parent_id in (" + placeholders(len(categoryIds)) + ") and deleted_at is null " +
+		"union select category.id from category join descendant on category.parent_id = descendant.id where category.deleted_at is null" +
+		") select " + categoryColumns + " from category where id in (select id from descendant) order by id"
+
+	return repository.query(ctx, tx, categoryIds, SQL)
+}
+
+// Function for find all data with query that use category id as argument, nothing is found without id
+func (repository *CategoryRepositoryImpl) query(ctx context.Context, tx Tx, categoryIds []int, SQL string) ([]domain.Category, error) {
+	if len(categoryIds) == 0 {
+		return nil, nil
+	}
+
+	// (1) Create query context
+	args := make([]interface{}, len(categoryIds))
+	for i, id := range categoryIds {
+		args[i] = id
+	}
+	rows, err := sqlTx(tx).QueryContext(ctx, SQL, args...)
+	if err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+	defer rows.Close()
 
-		// (3) If no error, insert all data to var categories
+	// (2) Scan each row to category
+	var categories []domain.Category
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, exception.NewInternalError(err)
+		}
 		categories = append(categories, category)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, exception.NewInternalError(err)
+	}
+
+	return categories, nil
+}
+
+// Function Count data with follow the contract category repository
+func (repository *CategoryRepositoryImpl) Count(ctx context.Context, tx Tx, filter domain.CategoryFilter) (int, error) {
+	// (1) Create sql query with filter
+	where, args := categoryWhere(filter, false)
+	SQL := "select count(*) from category" + where
+
+	// (2) Get total data
+	var total int
+	err := sqlTx(tx).QueryRowContext(ctx, SQL, args...).Scan(&total)
+
+	// (3) If error return internal error
+	if err != nil {
+		return 0, exception.NewInternalError(err)
+	}
+
+	return total, nil
+}
+
+// Function Purge data with follow the contract category repository
+func (repository *CategoryRepositoryImpl) Purge(ctx context.Context, tx Tx, deletedBefore time.Time) (int, error) {
+	// (1) Create sql query
+	SQL := "delete from category where deleted_at is not null and deleted_at < ?"
+
+	// (2) Create context
+	result, err := sqlTx(tx).ExecContext(ctx, SQL, deletedBefore.UTC())
+	if err != nil {
+		return 0, exception.NewInternalError(err)
+	}
+
+	// (3) Get total deleted data
+	total, err := result.RowsAffected()
+	if err != nil {
+		return 0, exception.NewInternalError(err)
+	}
 
-	// (7) return all data category
-	return categories
+	return int(total), nil
 }
@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Category repository with in memory storage
+type CategoryRepositoryMemory struct {
+	DB *MemoryDB // Use in memory storage
+}
+
+func NewCategoryRepositoryMemory(db *MemoryDB) CategoryRepository {
+	return &CategoryRepositoryMemory{DB: db}
+}
+
+// Function for get table category in transaction
+func (repository *CategoryRepositoryMemory) table(tx Tx) *memoryTable {
+	return memoryTableOf(repository.DB, tx, "category")
+}
+
+// Function Save with follow the contract category repository
+func (repository *CategoryRepositoryMemory) Save(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error) {
+	table := repository.table(tx)
+	if err := checkMemoryName(table, category); err != nil {
+		return category, err
+	}
+
+	category.Id = table.nextId()
+	category.Version = 1
+	table.rows[category.Id] = category
+
+	return category, nil
+}
+
+// Function SaveAll with follow the contract category repository
+func (repository *CategoryRepositoryMemory) SaveAll(ctx context.Context, tx Tx, categories []domain.Category) ([]domain.Category, error) {
+	saved := make([]domain.Category, len(categories))
+	for i, category := range categories {
+		var err error
+		saved[i], err = repository.Save(ctx, tx, category)
+		if err != nil {
+			return categories, err
+		}
+	}
+
+	return saved, nil
+}
+
+// Function Update with follow the contract category repository
+func (repository *CategoryRepositoryMemory) Update(ctx context.Context, tx Tx, category domain.Category) (domain.Category, error) {
+	table := repository.table(tx)
+
+	if err := checkMemoryVersion(table, category); err != nil {
+		return category, err
+	}
+	if err := checkMemoryName(table, category); err != nil {
+		return category, err
+	}
+
+	category.Version++
+	table.rows[category.Id] = category
+
+	return category, nil
+}
+
+// Function UpdateAll with follow the contract category repository, no category is updated
+// when version of one category is not same
+func (repository *CategoryRepositoryMemory) UpdateAll(ctx context.Context, tx Tx, categories []domain.Category) ([]domain.Category, error) {
+	table := repository.table(tx)
+
+	for _, category := range categories {
+		if err := checkMemoryVersion(table, category); err != nil {
+			return categories, err
+		}
+	}
+	if err := checkMemoryName(table, categories...); err != nil {
+		return categories, err
+	}
+
+	updated := make([]domain.Category, len(categories))
+	for i, category := range categories {
+		category.Version++
+		table.rows[category.Id] = category
+		updated[i] = category
+	}
+
+	return updated, nil
+}
+
+// Function Delete with follow the contract category repository
+func (repository *CategoryRepositoryMemory) Delete(ctx context.Context, tx Tx, category domain.Category) error {
+	table := repository.table(tx)
+
+	if err := checkMemoryVersion(table, category); err != nil {
+		return err
+	}
+
+	deleted := table.rows[category.Id].(domain.Category)
+	deleted.DeletedAt = category.DeletedAt
+	deleted.UpdatedAt = category.UpdatedAt
+	deleted.UpdatedBy = category.UpdatedBy
+	deleted.Version++
+	table.rows[category.Id] = deleted
+
+	return nil
+}
+
+// Function for check whether category in table still has the same version, same as checkVersionUpdated
+func checkMemoryVersion(table *memoryTable, category domain.Category) error {
+	row, ok := table.rows[category.Id]
+	if !ok || row.(domain.Category).Version != category.Version {
+		return exception.NewPreconditionFailedError("category has been modified, get the latest version and try again")
+	}
+
+	return nil
+}
+
+// Function for check name of active category is unique after categories is written, same as unique
+// index of name in database
+func checkMemoryName(table *memoryTable, categories ...domain.Category) error {
+	written := map[int]domain.Category{}
+	for _, category := range categories {
+		written[category.Id] = category
+	}
+
+	names := map[string]bool{}
+	check := func(category domain.Category) error {
+		if category.Deleted() {
+			return nil
+		}
+		name := strings.ToLower(category.Name)
+		if names[name] {
+			return duplicateNameError()
+		}
+		names[name] = true
+		return nil
+	}
+	for id, row := range table.rows {
+		if _, ok := written[id]; !ok {
+			if err := check(row.(domain.Category)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, category := range categories {
+		if err := check(category); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Function Find data by id with follow the contract category repository
+func (repository *CategoryRepositoryMemory) FindById(ctx context.Context, tx Tx, categoryId int) (domain.Category, error) {
+	category, err := repository.FindByIdWithDeleted(ctx, tx, categoryId)
+	if err == nil && category.Deleted() {
+		return domain.Category{}, exception.NewNotFoundError("category is not found")
+	}
+
+	return category, err
+}
+
+// Function Find data by id include deleted data with follow the contract category repository
+func (repository *CategoryRepositoryMemory) FindByIdWithDeleted(ctx context.Context, tx Tx, categoryId int) (domain.Category, error) {
+	row, ok := repository.table(tx).rows[categoryId]
+	if !ok {
+		return domain.Category{}, exception.NewNotFoundError("category is not found")
+	}
+
+	return row.(domain.Category), nil
+}
+
+// Function Find data by name with follow the contract category repository
+func (repository *CategoryRepositoryMemory) FindByName(ctx context.Context, tx Tx, name string) (domain.Category, error) {
+	for _, row := range repository.table(tx).rows {
+		if category := row.(domain.Category); !category.Deleted() && strings.EqualFold(category.Name, name) {
+			return category, nil
+		}
+	}
+
+	return domain.Category{}, exception.NewNotFoundError("category is not found")
+}
+
+// Function for get all data match with filter, ordered by sort field in filter
+func (repository *CategoryRepositoryMemory) filter(tx Tx, filter domain.CategoryFilter, withCursor bool) []domain.Category {
+	var categories []domain.Category
+	for _, row := range repository.table(tx).rows {
+		category := row.(domain.Category)
+		if !filter.WithDeleted && category.Deleted() {
+			continue
+		}
+		if filter.ParentId > 0 && category.ParentId != filter.ParentId {
+			continue
+		}
+		if len(filter.Ids) > 0 && !containsId(filter.Ids, category.Id) {
+			continue
+		}
+		if len(filter.Names) > 0 && !containsName(filter.Names, category.Name) {
+			continue
+		}
+		if withCursor && filter.AfterId > 0 && category.Id <= filter.AfterId {
+			continue
+		}
+		if withCursor && filter.BeforeId > 0 && category.Id >= filter.BeforeId {
+			continue
+		}
+		if !filter.UpdatedSince.IsZero() && category.UpdatedAt.Before(filter.UpdatedSince) {
+			continue
+		}
+		if filter.Query != "" {
+			name := strings.ToLower(category.Name)
+			query := strings.ToLower(filter.Query)
+			if filter.MatchPrefix && !strings.HasPrefix(name, query) {
+				continue
+			}
+			if !filter.MatchPrefix && !strings.Contains(name, query) {
+				continue
+			}
+		}
+
+		categories = append(categories, category)
+	}
+
+	// Sort same as database, name is compared case insensitive and id as second order.
+	// Cursor before always sorted by id
+	sort.Slice(categories, func(i, j int) bool {
+		less := categories[i].Id < categories[j].Id
+		if filter.BeforeId == 0 {
+			if compare := compareCategory(categories[i], categories[j], filter.Sort); compare != 0 {
+				less = compare < 0
+			}
+		}
+		if filter.Desc && filter.BeforeId == 0 {
+			return !less
+		}
+		return less
+	})
+
+	return categories
+}
+
+// Function Find all data with follow the contract category repository
+func (repository *CategoryRepositoryMemory) FindAll(ctx context.Context, tx Tx, filter domain.CategoryFilter) ([]domain.Category, error) {
+	categories := repository.filter(tx, filter, true)
+
+	if filter.Limit <= 0 {
+		return categories, nil
+	}
+
+	// For cursor before, take the last data before cursor
+	if filter.BeforeId > 0 {
+		if len(categories) > filter.Limit {
+			categories = categories[len(categories)-filter.Limit:]
+		}
+		return categories, nil
+	}
+
+	if filter.Offset < 0 || filter.Offset >= len(categories) {
+		return nil, nil
+	}
+	categories = categories[filter.Offset:]
+	if len(categories) > filter.Limit {
+		categories = categories[:filter.Limit]
+	}
+
+	return categories, nil
+}
+
+// Function FindAncestors with follow the contract category repository
+func (repository *CategoryRepositoryMemory) FindAncestors(ctx context.Context, tx Tx, categoryIds []int) ([]domain.Category, error) {
+	table := repository.table(tx)
+	found := map[int]bool{}
+	var categories []domain.Category
+
+	// Walk parent of each category, stop at category that already found so data with cycle not loop
+	for _, categoryId := range categoryIds {
+		for categoryId > 0 && !found[categoryId] {
+			row, ok := table.rows[categoryId]
+			if !ok || row.(domain.Category).Deleted() {
+				break
+			}
+
+			category := row.(domain.Category)
+			found[categoryId] = true
+			categories = append(categories, category)
+			categoryId = category.ParentId
+		}
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Id < categories[j].Id })
+	return categories, nil
+}
+
+// Function FindDescendants with follow the contract category repository
+func (repository *CategoryRepositoryMemory) FindDescendants(ctx context.Context, tx Tx, categoryIds []int) ([]domain.Category, error) {
+	all := repository.filter(tx, domain.CategoryFilter{}, false)
+	parents := map[int]bool{}
+	for _, categoryId := range categoryIds {
+		parents[categoryId] = true
+	}
+
+	// Find children of the found category until nothing new is found
+	found := map[int]bool{}
+	var categories []domain.Category
+	for changed := true; changed; {
+		changed = false
+		for _, category := range all {
+			if !found[category.Id] && parents[category.ParentId] {
+				found[category.Id] = true
+				parents[category.Id] = true
+				categories = append(categories, category)
+				changed = true
+			}
+		}
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Id < categories[j].Id })
+	return categories, nil
+}
+
+// Function for check whether id is one of the ids
+func containsId(ids []int, id int) bool {
+	for _, value := range ids {
+		if value == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Function for check whether name is one of the names case insensitive
+func containsName(names []string, name string) bool {
+	for _, value := range names {
+		if strings.EqualFold(value, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function FindEach with follow the contract category repository
+func (repository *CategoryRepositoryMemory) FindEach(ctx context.Context, tx Tx, filter domain.CategoryFilter, fn func(category domain.Category) error) error {
+	categories, _ := repository.FindAll(ctx, tx, filter)
+	for _, category := range categories {
+		if err := fn(category); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Function Count data with follow the contract category repository
+func (repository *CategoryRepositoryMemory) Count(ctx context.Context, tx Tx, filter domain.CategoryFilter) (int, error) {
+	return len(repository.filter(tx, filter, false)), nil
+}
+
+// Function Purge data with follow the contract category repository
+func (repository *CategoryRepositoryMemory) Purge(ctx context.Context, tx Tx, deletedBefore time.Time) (int, error) {
+	table := repository.table(tx)
+
+	total := 0
+	for id, row := range table.rows {
+		if category := row.(domain.Category); category.Deleted() && category.DeletedAt.Before(deletedBefore) {
+			delete(table.rows, id)
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+// Function for compare category by sort field, return 0 when value is same
+func compareCategory(a domain.Category, b domain.Category, sort string) int {
+	switch sort {
+	case "name":
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case "created_at":
+		return compareTime(a.CreatedAt, b.CreatedAt)
+	case "updated_at":
+		return compareTime(a.UpdatedAt, b.UpdatedAt)
+	default:
+		return 0
+	}
+}
+
+// Function for compare time, return 0 when time is same
+func compareTime(a time.Time, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Contract for repository idempotency key
+type IdempotencyKeyRepository interface {
+	// Contract function Save for insert data, return exception.ConflictError if key is already saved for the principal
+	Save(ctx context.Context, tx Tx, idempotencyKey domain.IdempotencyKey) (domain.IdempotencyKey, error)
+	// Contract function Update for update data
+	Update(ctx context.Context, tx Tx, idempotencyKey domain.IdempotencyKey) (domain.IdempotencyKey, error)
+	// Contract function Delete for delete data
+	Delete(ctx context.Context, tx Tx, idempotencyKey domain.IdempotencyKey) error
+	// Contract function DeleteExpired for delete all data expired at or before time, return total deleted data
+	DeleteExpired(ctx context.Context, tx Tx, now time.Time) (int, error)
+	// Contract function FindByKey for find data based on principal and key, return exception.NotFoundError if data is not available
+	FindByKey(ctx context.Context, tx Tx, principal string, key string) (domain.IdempotencyKey, error)
+}
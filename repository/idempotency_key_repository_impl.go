@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+type IdempotencyKeyRepositoryImpl struct {
+}
+
+func NewIdempotencyKeyRepository() IdempotencyKeyRepository {
+	return &IdempotencyKeyRepositoryImpl{}
+}
+
+// Column of table idempotency_key, same order with function scanIdempotencyKey
+const idempotencyKeyColumns = "id, idempotency_key, principal, fingerprint, status_code, header, body, created_at, expires_at"
+
+// Function for scan one row of table idempotency_key
+func scanIdempotencyKey(scanner interface{ Scan(...interface{}) error }) (domain.IdempotencyKey, error) {
+	idempotencyKey := domain.IdempotencyKey{}
+	var header sql.NullString
+	var createdAt, expiresAt helper.NullTime
+
+	err := scanner.Scan(&idempotencyKey.Id, &idempotencyKey.Key, &idempotencyKey.Principal, &idempotencyKey.Fingerprint, &idempotencyKey.StatusCode, &header, &idempotencyKey.Body, &createdAt, &expiresAt)
+	if err != nil {
+		return idempotencyKey, err
+	}
+	idempotencyKey.CreatedAt = createdAt.Time
+	idempotencyKey.ExpiresAt = expiresAt.Time
+
+	if header.Valid {
+		err = json.Unmarshal([]byte(header.String), &idempotencyKey.Header)
+	}
+
+	return idempotencyKey, err
+}
+
+// Function for convert header of response to JSON, empty header is null
+func idempotencyKeyHeader(header map[string]string) (interface{}, error) {
+	if len(header) == 0 {
+		return nil, nil
+	}
+
+	text, err := json.Marshal(header)
+	return string(text), err
+}
+
+// Function Save with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryImpl) Save(ctx context.Context, tx Tx, idempotencyKey domain.IdempotencyKey) (domain.IdempotencyKey, error) {
+	header, err := idempotencyKeyHeader(idempotencyKey.Header)
+	if err != nil {
+		return idempotencyKey, exception.NewInternalError(err)
+	}
+
+	// (1) Create sql query
+	SQL := "insert into idempotency_key(idempotency_key, principal, fingerprint, status_code, header, body, created_at, expires_at) values (?, ?, ?, ?, ?, ?, ?, ?)"
+
+	// (2) Create context, key that already saved by other request is conflict
+	result, err := sqlTx(tx).ExecContext(ctx, SQL, idempotencyKey.Key, idempotencyKey.Principal, idempotencyKey.Fingerprint, idempotencyKey.StatusCode, header, idempotencyKey.Body, idempotencyKey.CreatedAt.UTC(), idempotencyKey.ExpiresAt.UTC())
+	if isDuplicateEntry(err) {
+		return idempotencyKey, exception.NewConflictError("idempotency key is already saved")
+	}
+	if err != nil {
+		return idempotencyKey, exception.NewInternalError(err)
+	}
+
+	// (3) If success, get last insert id
+	id, err := result.LastInsertId()
+	if err != nil {
+		return idempotencyKey, exception.NewInternalError(err)
+	}
+	idempotencyKey.Id = int(id)
+
+	return idempotencyKey, nil
+}
+
+// Function Update with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryImpl) Update(ctx context.Context, tx Tx, idempotencyKey domain.IdempotencyKey) (domain.IdempotencyKey, error) {
+	header, err := idempotencyKeyHeader(idempotencyKey.Header)
+	if err != nil {
+		return idempotencyKey, exception.NewInternalError(err)
+	}
+
+	// (1) Create sql query
+	SQL := "update idempotency_key set fingerprint = ?, status_code = ?, header = ?, body = ?, expires_at = ? where id = ?"
+
+	// (2) Create context
+	_, err = sqlTx(tx).ExecContext(ctx, SQL, idempotencyKey.Fingerprint, idempotencyKey.StatusCode, header, idempotencyKey.Body, idempotencyKey.ExpiresAt.UTC(), idempotencyKey.Id)
+	if err != nil {
+		return idempotencyKey, exception.NewInternalError(err)
+	}
+
+	return idempotencyKey, nil
+}
+
+// Function Delete with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryImpl) Delete(ctx context.Context, tx Tx, idempotencyKey domain.IdempotencyKey) error {
+	_, err := sqlTx(tx).ExecContext(ctx, "delete from idempotency_key where id = ?", idempotencyKey.Id)
+	if err != nil {
+		return exception.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// Function Delete expired data with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryImpl) DeleteExpired(ctx context.Context, tx Tx, now time.Time) (int, error) {
+	result, err := sqlTx(tx).ExecContext(ctx, "delete from idempotency_key where expires_at <= ?", now.UTC())
+	if err != nil {
+		return 0, exception.NewInternalError(err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, exception.NewInternalError(err)
+	}
+
+	return int(deleted), nil
+}
+
+// Function Find data by principal and key with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryImpl) FindByKey(ctx context.Context, tx Tx, principal string, key string) (domain.IdempotencyKey, error) {
+	SQL := "select " + idempotencyKeyColumns + " from idempotency_key where principal = ? and idempotency_key = ?"
+
+	idempotencyKey, err := scanIdempotencyKey(sqlTx(tx).QueryRowContext(ctx, SQL, principal, key))
+	if err == sql.ErrNoRows {
+		return idempotencyKey, exception.NewNotFoundError("idempotency key is not found")
+	}
+	if err != nil {
+		return idempotencyKey, exception.NewInternalError(err)
+	}
+
+	return idempotencyKey, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Idempotency key repository with in memory storage
+type IdempotencyKeyRepositoryMemory struct {
+	DB *MemoryDB // Use in memory storage
+}
+
+func NewIdempotencyKeyRepositoryMemory(db *MemoryDB) IdempotencyKeyRepository {
+	return &IdempotencyKeyRepositoryMemory{DB: db}
+}
+
+// Function for get table idempotency_key in transaction
+func (repository *IdempotencyKeyRepositoryMemory) table(tx Tx) *memoryTable {
+	return memoryTableOf(repository.DB, tx, "idempotency_key")
+}
+
+// Function Save with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryMemory) Save(ctx context.Context, tx Tx, idempotencyKey domain.IdempotencyKey) (domain.IdempotencyKey, error) {
+	// (1) Key is unique for each principal, same as unique key in database
+	if _, err := repository.FindByKey(ctx, tx, idempotencyKey.Principal, idempotencyKey.Key); err == nil {
+		return idempotencyKey, exception.NewConflictError("idempotency key is already saved")
+	}
+
+	// (2) Save with new id
+	table := repository.table(tx)
+	idempotencyKey.Id = table.nextId()
+	table.rows[idempotencyKey.Id] = idempotencyKey
+
+	return idempotencyKey, nil
+}
+
+// Function Update with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryMemory) Update(ctx context.Context, tx Tx, idempotencyKey domain.IdempotencyKey) (domain.IdempotencyKey, error) {
+	table := repository.table(tx)
+
+	if _, ok := table.rows[idempotencyKey.Id]; ok {
+		table.rows[idempotencyKey.Id] = idempotencyKey
+	}
+
+	return idempotencyKey, nil
+}
+
+// Function Delete with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryMemory) Delete(ctx context.Context, tx Tx, idempotencyKey domain.IdempotencyKey) error {
+	delete(repository.table(tx).rows, idempotencyKey.Id)
+
+	return nil
+}
+
+// Function Delete expired data with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryMemory) DeleteExpired(ctx context.Context, tx Tx, now time.Time) (int, error) {
+	table := repository.table(tx)
+
+	deleted := 0
+	for id, row := range table.rows {
+		if !row.(domain.IdempotencyKey).ExpiresAt.After(now) {
+			delete(table.rows, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// Function Find data by principal and key with follow the contract idempotency key repository
+func (repository *IdempotencyKeyRepositoryMemory) FindByKey(ctx context.Context, tx Tx, principal string, key string) (domain.IdempotencyKey, error) {
+	for _, row := range repository.table(tx).rows {
+		if idempotencyKey := row.(domain.IdempotencyKey); idempotencyKey.Principal == principal && idempotencyKey.Key == key {
+			return idempotencyKey, nil
+		}
+	}
+
+	return domain.IdempotencyKey{}, exception.NewNotFoundError("idempotency key is not found")
+}
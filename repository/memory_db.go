@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// In memory storage, use for test and local run without database.
+// Only one transaction can run at the same time, the next transaction will wait
+// until the running transaction is commit or rollback.
+type MemoryDB struct {
+	mutex  sync.Mutex
+	tables map[string]*memoryTable
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		tables: map[string]*memoryTable{},
+	}
+}
+
+// Table in memory storage, row is saved by id
+type memoryTable struct {
+	rows   map[int]interface{}
+	lastId int
+}
+
+// Function for copy table, so changes in transaction not change the storage
+func (table *memoryTable) copy() *memoryTable {
+	rows := make(map[int]interface{}, len(table.rows))
+	for id, row := range table.rows {
+		rows[id] = row
+	}
+
+	return &memoryTable{rows: rows, lastId: table.lastId}
+}
+
+// Function for get next id, same as auto increment in database
+func (table *memoryTable) nextId() int {
+	table.lastId++
+	return table.lastId
+}
+
+// Transaction for in memory storage, all changes is saved to copy of table
+// and only written to the storage after commit
+type memoryTx struct {
+	db     *MemoryDB
+	tables map[string]*memoryTable
+	done   bool
+}
+
+// Function Begin with follow the contract tx beginner
+func (db *MemoryDB) Begin(ctx context.Context) (Tx, error) {
+	// Lock storage until transaction is done
+	db.mutex.Lock()
+
+	return &memoryTx{
+		db:     db,
+		tables: map[string]*memoryTable{},
+	}, nil
+}
+
+// Function for get table in transaction, table is copied when used for the first time
+func (tx *memoryTx) table(name string) *memoryTable {
+	if table, ok := tx.tables[name]; ok {
+		return table
+	}
+
+	table, ok := tx.db.tables[name]
+	if ok {
+		table = table.copy()
+	} else {
+		table = &memoryTable{rows: map[int]interface{}{}}
+	}
+	tx.tables[name] = table
+
+	return table
+}
+
+// Function Commit for write all changes to the storage
+func (tx *memoryTx) Commit() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	for name, table := range tx.tables {
+		tx.db.tables[name] = table
+	}
+	tx.db.mutex.Unlock()
+
+	return nil
+}
+
+// Function Rollback for discard all changes
+func (tx *memoryTx) Rollback() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	tx.db.mutex.Unlock()
+
+	return nil
+}
+
+// Function for get table from transaction repository, transaction must be begin from db
+func memoryTableOf(db *MemoryDB, tx Tx, name string) *memoryTable {
+	memoryTx, ok := tx.(*memoryTx)
+	if !ok || memoryTx.db != db {
+		panic("repository: transaction is not begin from this memory storage")
+	}
+	if memoryTx.done {
+		panic(sql.ErrTxDone)
+	}
+
+	return memoryTx.table(name)
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Error number of MySQL for insert or update row with duplicate unique key
+const mysqlDuplicateEntry = 1062
+
+// Contract for transaction used by repository
+type Tx interface {
+	// Contract function Commit for save all changes in transaction
+	Commit() error
+	// Contract function Rollback for discard all changes in transaction
+	Rollback() error
+}
+
+// Contract for storage that can begin new transaction
+type TxBeginner interface {
+	// Contract function Begin for create new transaction
+	Begin(ctx context.Context) (Tx, error)
+}
+
+type SqlTxBeginner struct {
+	DB *sql.DB // Use Sql driver
+}
+
+func NewSqlTxBeginner(db *sql.DB) TxBeginner {
+	return &SqlTxBeginner{DB: db}
+}
+
+// Function Begin with follow the contract tx beginner
+func (beginner *SqlTxBeginner) Begin(ctx context.Context) (Tx, error) {
+	return beginner.DB.BeginTx(ctx, nil)
+}
+
+// Function for get *sql.Tx from transaction repository
+func sqlTx(tx Tx) *sql.Tx {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		panic("repository: transaction is not *sql.Tx")
+	}
+
+	return sqlTx
+}
+
+// Function for check whether error is MySQL error of duplicate unique key
+func isDuplicateEntry(err error) bool {
+	var mysqlError *mysql.MySQLError
+	return errors.As(err, &mysqlError) && mysqlError.Number == mysqlDuplicateEntry
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+)
+
+// Contract for unit of work, run all operation of repository in one transaction
+// without knowing the storage behind it
+type UnitOfWork interface {
+	// Contract function Do for run fn in transaction.
+	// Transaction is commit if fn success, and rollback if fn return error or panic
+	Do(ctx context.Context, fn func(tx Tx) error) error
+}
+
+type UnitOfWorkImpl struct {
+	TxBeginner TxBeginner // Use storage for begin transaction
+}
+
+func NewUnitOfWork(txBeginner TxBeginner) UnitOfWork {
+	return &UnitOfWorkImpl{
+		TxBeginner: txBeginner,
+	}
+}
+
+// Function Do with follow the contract unit of work
+func (unitOfWork *UnitOfWorkImpl) Do(ctx context.Context, fn func(tx Tx) error) error {
+	// (1) Create transaction
+	tx, err := unitOfWork.TxBeginner.Begin(ctx)
+	// (2) If error, return internal error
+	if err != nil {
+		return exception.NewInternalError(err)
+	}
+
+	// (3) Rollback transaction if fn panic, and continue the panic
+	defer func() {
+		if message := recover(); message != nil {
+			tx.Rollback()
+			panic(message)
+		}
+	}()
+
+	// (4) Run fn, if error rollback transaction
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// (5) If success, commit transaction
+	if err := tx.Commit(); err != nil {
+		return exception.NewInternalError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+)
+
+type ApiKeyService interface {
+	Create(ctx context.Context, request web.ApiKeyCreateRequest) (web.ApiKeyResponse, error)
+	Rotate(ctx context.Context, apiKeyId int) (web.ApiKeyResponse, error)
+	Revoke(ctx context.Context, apiKeyId int) error
+	FindAll(ctx context.Context) ([]web.ApiKeyResponse, error)
+	// Authenticate return API key that active for the key, and save the time key is used
+	Authenticate(ctx context.Context, key string) (domain.ApiKey, error)
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/repository"
+
+	"github.com/go-playground/validator"
+)
+
+// Prefix of issued API key, so the key easy to recognize
+const apiKeyPrefix = "ak_"
+
+type ApiKeyServiceImpl struct {
+	ApiKeyRepository repository.ApiKeyRepository // Use repository
+	UnitOfWork       repository.UnitOfWork       // Use unit of work for transaction
+	Validate         *validator.Validate         // Use validator
+}
+
+func NewApiKeyService(apiKeyRepository repository.ApiKeyRepository, unitOfWork repository.UnitOfWork, validate *validator.Validate) ApiKeyService {
+	return &ApiKeyServiceImpl{
+		ApiKeyRepository: apiKeyRepository,
+		UnitOfWork:       unitOfWork,
+		Validate:         validate,
+	}
+}
+
+// Function for hash API key, only the hash is saved to storage
+func HashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// Function for generate new random API key
+func generateApiKey() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", exception.NewInternalError(err)
+	}
+
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// Function for set new random key to API key, return the key
+func setNewKey(apiKey *domain.ApiKey) (string, error) {
+	key, err := generateApiKey()
+	if err != nil {
+		return "", err
+	}
+
+	apiKey.KeyHash = HashApiKey(key)
+	apiKey.KeyPrefix = key[:len(apiKeyPrefix)+6]
+
+	return key, nil
+}
+
+// Function service for process issue new API key
+func (service *ApiKeyServiceImpl) Create(ctx context.Context, request web.ApiKeyCreateRequest) (web.ApiKeyResponse, error) {
+	// (1) Run validate before create data
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return web.ApiKeyResponse{}, exception.NewValidationError(err)
+	}
+
+	// (2) Expired time must be in the future, API key without scope is granted all scope of category
+	now := time.Now().UTC()
+	apiKey := domain.ApiKey{
+		Name:      request.Name,
+		CreatedAt: now,
+		Scopes:    request.Scopes,
+	}
+	if len(apiKey.Scopes) == 0 {
+		apiKey.Scopes = domain.CategoryScopes
+	}
+	if request.ExpiresAt != nil {
+		if !request.ExpiresAt.After(now) {
+			return web.ApiKeyResponse{}, exception.NewFieldValidationError("expires_at", "gt", "now", "expires_at must be in the future")
+		}
+		apiKey.ExpiresAt = request.ExpiresAt.UTC()
+	}
+
+	// (3) Generate new key
+	key, err := setNewKey(&apiKey)
+	if err != nil {
+		return web.ApiKeyResponse{}, err
+	}
+
+	// (4) Save API key with use Repository
+	err = service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		apiKey, err = service.ApiKeyRepository.Save(ctx, tx, apiKey)
+		return err
+	})
+	if err != nil {
+		return web.ApiKeyResponse{}, err
+	}
+
+	// (5) Return response with key, key only can be seen at this time
+	apiKeyResponse := helper.ToApiKeyResponse(apiKey)
+	apiKeyResponse.Key = key
+	return apiKeyResponse, nil
+}
+
+// Function service for process rotate API key, the old key can not be used after rotated
+func (service *ApiKeyServiceImpl) Rotate(ctx context.Context, apiKeyId int) (web.ApiKeyResponse, error) {
+	var apiKey domain.ApiKey
+	var key string
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Find API key, return error not found if API key is not available
+		var err error
+		apiKey, err = service.ApiKeyRepository.FindById(ctx, tx, apiKeyId)
+		if err != nil {
+			return err
+		}
+
+		// (2) Revoked API key can not be rotated
+		if apiKey.Revoked {
+			return exception.NewConflictError("api key is revoked")
+		}
+
+		// (3) Generate new key and update API key
+		key, err = setNewKey(&apiKey)
+		if err != nil {
+			return err
+		}
+
+		apiKey, err = service.ApiKeyRepository.Update(ctx, tx, apiKey)
+		return err
+	})
+	if err != nil {
+		return web.ApiKeyResponse{}, err
+	}
+
+	apiKeyResponse := helper.ToApiKeyResponse(apiKey)
+	apiKeyResponse.Key = key
+	return apiKeyResponse, nil
+}
+
+// Function service for process revoke API key
+func (service *ApiKeyServiceImpl) Revoke(ctx context.Context, apiKeyId int) error {
+	return service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Find API key, return error not found if API key is not available
+		apiKey, err := service.ApiKeyRepository.FindById(ctx, tx, apiKeyId)
+		if err != nil {
+			return err
+		}
+
+		// (2) Mark API key as revoked, data is kept for audit
+		apiKey.Revoked = true
+		_, err = service.ApiKeyRepository.Update(ctx, tx, apiKey)
+		return err
+	})
+}
+
+// Function service for process find all API key
+func (service *ApiKeyServiceImpl) FindAll(ctx context.Context) ([]web.ApiKeyResponse, error) {
+	var apiKeys []domain.ApiKey
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		var err error
+		apiKeys, err = service.ApiKeyRepository.FindAll(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ToApiKeyResponses(apiKeys), nil
+}
+
+// Function service for process authenticate API key
+func (service *ApiKeyServiceImpl) Authenticate(ctx context.Context, key string) (domain.ApiKey, error) {
+	var apiKey domain.ApiKey
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Find API key by hash of key
+		var err error
+		apiKey, err = service.ApiKeyRepository.FindByHash(ctx, tx, HashApiKey(key))
+		if err != nil {
+			return err
+		}
+
+		// (2) Revoked and expired API key is same as not found
+		now := time.Now().UTC()
+		if !apiKey.Active(now) {
+			return exception.NewNotFoundError("api key is not found")
+		}
+
+		// (3) Save the time API key is used
+		apiKey.LastUsedAt = now
+		apiKey, err = service.ApiKeyRepository.Update(ctx, tx, apiKey)
+		return err
+	})
+	if err != nil {
+		return domain.ApiKey{}, err
+	}
+
+	return apiKey, nil
+}
@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/repository"
+)
+
+// Max category written in one query, so total parameter of query is not more than limit of database
+const bulkWriteSize = 500
+
+// Result of one item in bulk request, Err is nil when item succeeded.
+// Category is nil when item is not written, e.g. in dry run.
+type CategoryBulkResult struct {
+	Category *web.CategoryResponse
+	Err      error
+}
+
+// Function service for process create many category in one transaction
+func (service *CategoryServiceImpl) BulkCreate(ctx context.Context, request web.CategoryBulkCreateRequest) ([]CategoryBulkResult, error) {
+	// (1) Run validate for total item, each item is validated by itself
+	if err := service.Validate.Struct(request); err != nil {
+		return nil, exception.NewValidationError(err)
+	}
+
+	// (2) Create each item in snapshot
+	scope := categoryScope{}
+	for _, item := range request.Items {
+		scope.touch(0, item.ParentId, item.Name)
+	}
+	return service.bulk(ctx, scope, len(request.Items), request.BestEffort, false, func(snapshot *categorySnapshot, index int) (int, error) {
+		return service.createItem(ctx, snapshot, request.Items[index])
+	})
+}
+
+// Function service for process update many category in one transaction
+func (service *CategoryServiceImpl) BulkUpdate(ctx context.Context, request web.CategoryBulkUpdateRequest) ([]CategoryBulkResult, error) {
+	// (1) Run validate for total item, each item is validated by itself
+	if err := service.Validate.Struct(request); err != nil {
+		return nil, exception.NewValidationError(err)
+	}
+
+	// (2) Update each item in snapshot
+	scope := categoryScope{}
+	for _, item := range request.Items {
+		scope.touch(item.Id, item.ParentId, item.Name)
+	}
+	return service.bulk(ctx, scope, len(request.Items), request.BestEffort, false, func(snapshot *categorySnapshot, index int) (int, error) {
+		return service.updateItem(ctx, snapshot, request.Items[index])
+	})
+}
+
+// Function service for process delete many category in one transaction, category is soft deleted
+func (service *CategoryServiceImpl) BulkDelete(ctx context.Context, request web.CategoryBulkDeleteRequest) ([]CategoryBulkResult, error) {
+	// (1) Run validate for total item, each item is validated by itself
+	if err := service.Validate.Struct(request); err != nil {
+		return nil, exception.NewValidationError(err)
+	}
+
+	now, principal := auditInfo(ctx)
+	scope := categoryScope{}
+	for _, item := range request.Items {
+		scope.touch(item.Id, 0, "")
+	}
+	return service.bulk(ctx, scope, len(request.Items), request.BestEffort, false, func(snapshot *categorySnapshot, index int) (int, error) {
+		// (2) Run validate before delete data
+		item := request.Items[index]
+		if err := service.Validate.Struct(item); err != nil {
+			return 0, exception.NewValidationError(err)
+		}
+
+		// (3) Find category, return error not found if category is not available
+		category, err := snapshot.find(item.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		// (4) Reject, delete or move children of category, same as delete one category
+		behavior := item.Children
+		if behavior == "" {
+			behavior = service.TreeConfig.DeleteChildren
+		}
+		levels := snapshot.descendants(category.Id)
+		if len(levels) > 0 {
+			switch behavior {
+			case DeleteChildrenCascade:
+				for _, level := range levels {
+					for _, descendant := range level {
+						descendant.UpdatedAt, descendant.UpdatedBy, descendant.DeletedAt = now, principal, now
+						snapshot.put(descendant)
+					}
+				}
+			case DeleteChildrenReparent:
+				for _, child := range levels[0] {
+					child.ParentId = category.ParentId
+					child.UpdatedAt, child.UpdatedBy = now, principal
+					snapshot.put(child)
+				}
+			default:
+				return 0, hasChildrenError()
+			}
+		}
+
+		// (5) Delete category, deleted is also an update for sync with updated_since
+		category.UpdatedAt, category.UpdatedBy, category.DeletedAt = now, principal, now
+		snapshot.put(category)
+		return category.Id, nil
+	})
+}
+
+// Function for check item of bulk create and add the category to snapshot, return the temporary id
+func (service *CategoryServiceImpl) createItem(ctx context.Context, snapshot *categorySnapshot, item web.CategoryCreateRequest) (int, error) {
+	// (1) Run validate before create data
+	if err := service.Validate.Struct(item); err != nil {
+		return 0, exception.NewValidationError(err)
+	}
+
+	// (2) Create new object category, name must not be used by other category or other item
+	now, principal := auditInfo(ctx)
+	category := domain.Category{
+		Name:      item.Name,
+		ParentId:  item.ParentId,
+		CreatedAt: now,
+		UpdatedAt: now,
+		CreatedBy: principal,
+		UpdatedBy: principal,
+	}
+	if err := snapshot.checkName(category); err != nil {
+		return 0, err
+	}
+	if err := snapshot.checkParent(category); err != nil {
+		return 0, err
+	}
+
+	return snapshot.add(category), nil
+}
+
+// Function for check item of bulk update and set the category to snapshot, return id of category
+func (service *CategoryServiceImpl) updateItem(ctx context.Context, snapshot *categorySnapshot, item web.CategoryUpdateRequest) (int, error) {
+	// (1) Run validate before update data
+	if err := service.Validate.Struct(item); err != nil {
+		return 0, exception.NewValidationError(err)
+	}
+
+	// (2) Find category, return error not found if category is not available
+	category, err := snapshot.find(item.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	// (3) Set request name and parent to object category, name must not be used by other category
+	category.Name = item.Name
+	category.ParentId = item.ParentId
+	category.UpdatedAt, category.UpdatedBy = auditInfo(ctx)
+	if err := snapshot.checkName(category); err != nil {
+		return 0, err
+	}
+	if err := snapshot.checkParent(category); err != nil {
+		return 0, err
+	}
+
+	snapshot.put(category)
+	return category.Id, nil
+}
+
+// Function for process item of bulk request in one transaction. Function apply check one item and
+// change the snapshot, then return id of the changed category. In atomic mode no item is written
+// when one of item failed, in best effort mode only succeeded item is written. In dry run no item
+// is written, but each item is still checked.
+func (service *CategoryServiceImpl) bulk(ctx context.Context, scope categoryScope, total int, bestEffort bool, dryRun bool, apply func(snapshot *categorySnapshot, index int) (int, error)) ([]CategoryBulkResult, error) {
+	results := make([]CategoryBulkResult, total)
+	var changes []categoryChange
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Find active category touched by item, so item is checked without query for each item
+		categories, err := service.findScope(ctx, tx, scope)
+		if err != nil {
+			return err
+		}
+		snapshot := newCategorySnapshot(categories, service.TreeConfig.MaxDepth)
+
+		// (2) Check and apply each item in order, failed item not change the snapshot
+		ids := make([]int, total)
+		failed := false
+		for i := range results {
+			ids[i], results[i].Err = apply(snapshot, i)
+			if results[i].Err != nil {
+				failed = true
+			}
+		}
+
+		// (3) In dry run no item is written, in atomic mode succeeded item is not written because other item failed
+		if dryRun {
+			return nil
+		}
+		if failed && !bestEffort {
+			for i := range results {
+				if results[i].Err == nil {
+					results[i].Err = exception.NewFailedDependencyError("item is not applied because other item failed")
+				}
+			}
+			return nil
+		}
+
+		// (4) Write all change, updated category is written with multi row query
+		if err := snapshot.flush(ctx, tx, service.CategoryRepository); err != nil {
+			return err
+		}
+		for i, id := range ids {
+			if results[i].Err == nil {
+				category := helper.ToCategoryResponse(snapshot.categories[id])
+				results[i].Category = &category
+			}
+		}
+		changes = snapshot.changes()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// (5) Publish the written category after transaction is committed
+	service.publish(changes...)
+	return results, nil
+}
+
+// Id and name of category touched by item of bulk request
+type categoryScope struct {
+	ids   []int
+	names []string
+}
+
+// Function for add id, parent id and name of one item to scope, 0 and empty name is ignored
+func (scope *categoryScope) touch(categoryId int, parentId int, name string) {
+	for _, id := range []int{categoryId, parentId} {
+		if id > 0 {
+			scope.ids = append(scope.ids, id)
+		}
+	}
+	if name != "" {
+		scope.names = append(scope.names, name)
+	}
+}
+
+// Function for find active category in scope, their ancestors and their descendants ordered by id.
+// Item can only move category in scope, so ancestor and descendant of the changed category is
+// always in snapshot, and category with the same name as item is found for check name.
+func (service *CategoryServiceImpl) findScope(ctx context.Context, tx repository.Tx, scope categoryScope) ([]domain.Category, error) {
+	// (1) Find category by id with their ancestors and their descendants
+	ancestors, err := service.CategoryRepository.FindAncestors(ctx, tx, scope.ids)
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := service.CategoryRepository.FindDescendants(ctx, tx, scope.ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// (2) Find category that use name of item
+	var named []domain.Category
+	if len(scope.names) > 0 {
+		named, err = service.CategoryRepository.FindAll(ctx, tx, domain.CategoryFilter{Names: scope.names})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// (3) Merge category found by each query, ordered by id
+	found := map[int]bool{}
+	var categories []domain.Category
+	for _, list := range [][]domain.Category{ancestors, descendants, named} {
+		for _, category := range list {
+			if !found[category.Id] {
+				found[category.Id] = true
+				categories = append(categories, category)
+			}
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Id < categories[j].Id })
+
+	return categories, nil
+}
+
+// Snapshot of active category in scope of bulk transaction. Each item is checked against the snapshot and
+// changed in the snapshot, so the next item see change of the previous item.
+type categorySnapshot struct {
+	categories map[int]domain.Category // Category by id, include category deleted by item
+	ids        []int                   // Id of category ordered by id, created category is the last
+	names      map[string]int          // Id of active category by name in lower case
+	created    []int                   // Temporary id of created category, a negative number
+	changed    []int                   // Id of updated or deleted category, in order of first change
+	isChanged  map[int]bool
+	maxDepth   int
+}
+
+// Function for create snapshot from active category ordered by id
+func newCategorySnapshot(categories []domain.Category, maxDepth int) *categorySnapshot {
+	snapshot := &categorySnapshot{
+		categories: map[int]domain.Category{},
+		names:      map[string]int{},
+		isChanged:  map[int]bool{},
+		maxDepth:   maxDepth,
+	}
+	for _, category := range categories {
+		snapshot.categories[category.Id] = category
+		snapshot.ids = append(snapshot.ids, category.Id)
+		snapshot.names[strings.ToLower(category.Name)] = category.Id
+	}
+
+	return snapshot
+}
+
+// Function for find active category by id
+func (snapshot *categorySnapshot) find(categoryId int) (domain.Category, error) {
+	category, ok := snapshot.categories[categoryId]
+	if !ok || category.Deleted() {
+		return domain.Category{}, exception.NewNotFoundError("category is not found")
+	}
+
+	return category, nil
+}
+
+// Function for check whether name of category is not used by other category or created item, case insensitive
+func (snapshot *categorySnapshot) checkName(category domain.Category) error {
+	existingId, ok := snapshot.names[strings.ToLower(category.Name)]
+	if !ok || existingId == category.Id {
+		return nil
+	}
+	if existingId < 0 {
+		return exception.NewConflictError("category name {0} is already used by other item in request", category.Name)
+	}
+
+	return exception.NewDuplicateError(existingId, "category name {0} is already used by category {1}", category.Name, strconv.Itoa(existingId))
+}
+
+// Function for check parent of category with the same rule as CategoryServiceImpl.checkParent
+func (snapshot *categorySnapshot) checkParent(category domain.Category) error {
+	if category.ParentId == 0 {
+		return nil
+	}
+
+	// (1) Parent must be available, and category can not be moved to itself or its descendant
+	depth := 1
+	visited := map[int]bool{}
+	for parentId := category.ParentId; parentId != 0 && !visited[parentId]; depth++ {
+		parent, err := snapshot.find(parentId)
+		if err != nil {
+			return parentNotFoundError()
+		}
+		if parent.Id == category.Id {
+			return parentCycleError()
+		}
+
+		visited[parentId] = true
+		parentId = parent.ParentId
+	}
+
+	// (2) Depth of the deepest descendant is depth of parent, the category and level of descendant
+	if category.Id != 0 {
+		depth += len(snapshot.descendants(category.Id))
+	}
+	if depth > snapshot.maxDepth {
+		return maxDepthError(snapshot.maxDepth)
+	}
+
+	return nil
+}
+
+// Function for find all active descendant of category, ordered by level
+func (snapshot *categorySnapshot) descendants(categoryId int) [][]domain.Category {
+	categories := make([]domain.Category, len(snapshot.ids))
+	for i, id := range snapshot.ids {
+		categories[i] = snapshot.categories[id]
+	}
+
+	return descendantLevels(categories, categoryId, snapshot.maxDepth)
+}
+
+// Function for add created category to snapshot, return the temporary id
+func (snapshot *categorySnapshot) add(category domain.Category) int {
+	category.Id = -(len(snapshot.created) + 1)
+	snapshot.created = append(snapshot.created, category.Id)
+	snapshot.ids = append(snapshot.ids, category.Id)
+	snapshot.put(category)
+
+	return category.Id
+}
+
+// Function for set changed category to snapshot, name of deleted category can be used again
+func (snapshot *categorySnapshot) put(category domain.Category) {
+	if previous, ok := snapshot.categories[category.Id]; ok {
+		if snapshot.names[strings.ToLower(previous.Name)] == category.Id {
+			delete(snapshot.names, strings.ToLower(previous.Name))
+		}
+	}
+	if category.Id > 0 && !snapshot.isChanged[category.Id] {
+		snapshot.isChanged[category.Id] = true
+		snapshot.changed = append(snapshot.changed, category.Id)
+	}
+	if !category.Deleted() {
+		snapshot.names[strings.ToLower(category.Name)] = category.Id
+	}
+
+	snapshot.categories[category.Id] = category
+}
+
+// Function for write created and changed category in batch, category in snapshot
+// is replaced with the written category, e.g. with id from database
+func (snapshot *categorySnapshot) flush(ctx context.Context, tx repository.Tx, categoryRepository repository.CategoryRepository) error {
+	write := func(ids []int, save func(ctx context.Context, tx repository.Tx, categories []domain.Category) ([]domain.Category, error)) error {
+		for start := 0; start < len(ids); start += bulkWriteSize {
+			end := start + bulkWriteSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+
+			categories := make([]domain.Category, end-start)
+			for i, id := range ids[start:end] {
+				categories[i] = snapshot.categories[id]
+			}
+
+			written, err := save(ctx, tx, categories)
+			if err != nil {
+				return err
+			}
+			for i, id := range ids[start:end] {
+				snapshot.categories[id] = written[i]
+			}
+		}
+		return nil
+	}
+
+	if err := write(snapshot.created, categoryRepository.SaveAll); err != nil {
+		return err
+	}
+
+	return write(snapshot.changed, categoryRepository.UpdateAll)
+}
+
+// Function for list the written category as change, created category is published first
+func (snapshot *categorySnapshot) changes() []categoryChange {
+	changes := make([]categoryChange, 0, len(snapshot.created)+len(snapshot.changed))
+	for _, id := range snapshot.created {
+		changes = append(changes, categoryChange{CategoryEventCreated, snapshot.categories[id]})
+	}
+	for _, id := range snapshot.changed {
+		eventType := CategoryEventUpdated
+		if snapshot.categories[id].Deleted() {
+			eventType = CategoryEventDeleted
+		}
+		changes = append(changes, categoryChange{eventType, snapshot.categories[id]})
+	}
+
+	return changes
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+// Change of category in transaction, published as event after transaction is committed
+type categoryChange struct {
+	eventType string
+	category  domain.Category
+}
+
+// Function service for process subscribe category event, event after lastEventId is replayed when available
+func (service *CategoryServiceImpl) Subscribe(lastEventId *int) *CategoryEventSubscription {
+	return service.EventBroker.Subscribe(lastEventId)
+}
+
+// Function for publish event of changed category, must be called after transaction is committed
+func (service *CategoryServiceImpl) publish(changes ...categoryChange) {
+	for _, change := range changes {
+		service.EventBroker.Publish(change.eventType, helper.ToCategoryResponse(change.category))
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/jabutech/go-crud-restful-api/model/web"
+)
+
+// Type of category event
+const (
+	CategoryEventCreated = "created"
+	CategoryEventUpdated = "updated"
+	CategoryEventDeleted = "deleted"
+	// Event before replayed event when event after Last-Event-ID is not available anymore,
+	// e.g. removed from replay buffer or server restarted. Client must load all category again.
+	CategoryEventReset = "reset"
+)
+
+const (
+	DefaultEventBufferSize  = 1000 // Total event kept for replay when buffer size is not set
+	subscriberEventCapacity = 64   // Event waiting to be sent to one subscriber, slower subscriber is closed
+)
+
+// Contract for broker that send category event to subscriber in the same process
+type CategoryEventBroker interface {
+	// Publish send event to all subscriber, id of event is set by broker
+	Publish(eventType string, category web.CategoryResponse)
+	// Subscribe receive the next event. When lastEventId is not nil, event after it is replayed
+	// from replay buffer. Subscription must be closed after used.
+	Subscribe(lastEventId *int) *CategoryEventSubscription
+}
+
+// Subscription of category event
+type CategoryEventSubscription struct {
+	Replay []web.CategoryEvent // Event after last event id, ordered by id
+	// Next event, closed when subscriber is too slow so client can resume with the last received id
+	Events <-chan web.CategoryEvent
+	close  func()
+}
+
+// Function for stop receive event
+func (subscription *CategoryEventSubscription) Close() {
+	subscription.close()
+}
+
+type CategoryEventBrokerImpl struct {
+	BufferSize  int // Max event kept for replay
+	mutex       sync.Mutex
+	events      []web.CategoryEvent // Replay buffer, ordered by id
+	lastId      int
+	subscribers map[chan web.CategoryEvent]bool
+}
+
+// Zero value of bufferSize is replaced with DefaultEventBufferSize
+func NewCategoryEventBroker(bufferSize int) CategoryEventBroker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBufferSize
+	}
+
+	return &CategoryEventBrokerImpl{
+		BufferSize:  bufferSize,
+		subscribers: map[chan web.CategoryEvent]bool{},
+	}
+}
+
+// Function Publish with follow the contract category event broker
+func (broker *CategoryEventBrokerImpl) Publish(eventType string, category web.CategoryResponse) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	// (1) Keep event in replay buffer, the oldest event is removed when buffer is full
+	broker.lastId++
+	event := web.CategoryEvent{Id: broker.lastId, Type: eventType, Category: &category}
+	broker.events = append(broker.events, event)
+	if len(broker.events) > broker.BufferSize {
+		broker.events = append([]web.CategoryEvent(nil), broker.events[len(broker.events)-broker.BufferSize:]...)
+	}
+
+	// (2) Send to all subscriber without wait, so slow subscriber not block the publisher
+	for subscriber := range broker.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(broker.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Function Subscribe with follow the contract category event broker
+func (broker *CategoryEventBrokerImpl) Subscribe(lastEventId *int) *CategoryEventSubscription {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	subscriber := make(chan web.CategoryEvent, subscriberEventCapacity)
+	broker.subscribers[subscriber] = true
+	subscription := &CategoryEventSubscription{
+		Events: subscriber,
+		close: func() {
+			broker.mutex.Lock()
+			defer broker.mutex.Unlock()
+			if broker.subscribers[subscriber] {
+				delete(broker.subscribers, subscriber)
+				close(subscriber)
+			}
+		},
+	}
+
+	// (1) Replay event after the last event id, all event in buffer is replayed after reset
+	// when the next event is not available anymore
+	if lastEventId != nil {
+		previousId := broker.lastId - len(broker.events)
+		if *lastEventId < previousId || *lastEventId > broker.lastId {
+			subscription.Replay = append(subscription.Replay, web.CategoryEvent{Id: previousId, Type: CategoryEventReset})
+			subscription.Replay = append(subscription.Replay, broker.events...)
+		} else {
+			subscription.Replay = append(subscription.Replay, broker.events[*lastEventId-previousId:]...)
+		}
+	}
+
+	return subscription
+}
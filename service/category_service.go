@@ -3,13 +3,31 @@ package service
 import (
 	"context"
 
+	"github.com/jabutech/go-crud-restful-api/helper"
 	"github.com/jabutech/go-crud-restful-api/model/web"
 )
 
 type CategoryService interface {
-	Create(ctx context.Context, request web.CategoryCreateRequest) web.CategoryResponse
-	Update(ctx context.Context, request web.CategoryUpdateRequest) web.CategoryResponse
-	Delete(ctx context.Context, categoryId int)
-	FindById(ctx context.Context, categoryId int) web.CategoryResponse
-	FindAll(ctx context.Context) []web.CategoryResponse
+	Create(ctx context.Context, request web.CategoryCreateRequest) (web.CategoryResponse, error)
+	Update(ctx context.Context, request web.CategoryUpdateRequest) (web.CategoryResponse, error)
+	// Patch category with JSON Merge Patch or JSON Patch, ifMatch is version from header If-Match
+	Patch(ctx context.Context, categoryId int, patch helper.Patch, ifMatch []int) (web.CategoryResponse, error)
+	Delete(ctx context.Context, request web.CategoryDeleteRequest) error
+	Restore(ctx context.Context, categoryId int) (web.CategoryResponse, error)
+	FindById(ctx context.Context, categoryId int) (web.CategoryResponse, error)
+	FindAll(ctx context.Context, request web.CategoryListRequest) (web.CategoryPageResponse, error)
+	FindChildren(ctx context.Context, categoryId int) ([]web.CategoryResponse, error)
+	// Find ancestors of category ordered from root category to parent, e.g. for breadcrumb
+	FindAncestors(ctx context.Context, categoryId int) ([]web.CategoryResponse, error)
+	FindTree(ctx context.Context) ([]web.CategoryTreeResponse, error)
+	Purge(ctx context.Context, request web.CategoryPurgeRequest) (web.CategoryPurgeResponse, error)
+	// Create, update or delete many category in one transaction, result has the same order as item in request
+	BulkCreate(ctx context.Context, request web.CategoryBulkCreateRequest) ([]CategoryBulkResult, error)
+	BulkUpdate(ctx context.Context, request web.CategoryBulkUpdateRequest) ([]CategoryBulkResult, error)
+	BulkDelete(ctx context.Context, request web.CategoryBulkDeleteRequest) ([]CategoryBulkResult, error)
+	Export(ctx context.Context, write func(category web.CategoryResponse) error) error
+	// Import category from file, result has the same order as row in request
+	Import(ctx context.Context, request web.CategoryImportRequest) ([]CategoryBulkResult, error)
+	// Subscribe event of created, updated and deleted category, event after lastEventId is replayed when not nil
+	Subscribe(lastEventId *int) *CategoryEventSubscription
 }
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"time"
 
 	"github.com/jabutech/go-crud-restful-api/exception"
 	"github.com/jabutech/go-crud-restful-api/helper"
@@ -13,133 +17,515 @@ import (
 	"github.com/go-playground/validator"
 )
 
+const (
+	DefaultPageSize       = 20                  // Page size when request not set size
+	MaxPageSize           = 100                 // Max page size allowed by server
+	DefaultPurgeRetention = 30 * 24 * time.Hour // Deleted category is kept at least 30 days when purge
+)
+
 type CategoryServiceImpl struct {
 	CategoryRepository repository.CategoryRepository // Use repository
-	DB                 *sql.DB                       // Use Sql driver
+	UnitOfWork         repository.UnitOfWork         // Use unit of work for transaction
 	Validate           *validator.Validate           // Use validator
+	TreeConfig         CategoryTreeConfig            // Use config of category hierarchy
+	EventBroker        CategoryEventBroker           // Use broker for publish change of category
 }
 
-func NewCategoryService(categoryRepository repository.CategoryRepository, DB *sql.DB, validate *validator.Validate) CategoryService {
+// Zero value of treeConfig is replaced with default config, nil eventBroker is replaced with new broker
+func NewCategoryService(categoryRepository repository.CategoryRepository, unitOfWork repository.UnitOfWork, validate *validator.Validate, treeConfig CategoryTreeConfig, eventBroker CategoryEventBroker) CategoryService {
+	if treeConfig.MaxDepth <= 0 {
+		treeConfig.MaxDepth = DefaultMaxDepth
+	}
+	if treeConfig.DeleteChildren == "" {
+		treeConfig.DeleteChildren = DeleteChildrenReject
+	}
+	if eventBroker == nil {
+		eventBroker = NewCategoryEventBroker(DefaultEventBufferSize)
+	}
+
 	return &CategoryServiceImpl{
 		CategoryRepository: categoryRepository,
-		DB:                 DB,
+		UnitOfWork:         unitOfWork,
 		Validate:           validate,
+		TreeConfig:         treeConfig,
+		EventBroker:        eventBroker,
 	}
 }
 
 // Function service for proses create new category
-func (service *CategoryServiceImpl) Create(ctx context.Context, request web.CategoryCreateRequest) web.CategoryResponse {
+func (service *CategoryServiceImpl) Create(ctx context.Context, request web.CategoryCreateRequest) (web.CategoryResponse, error) {
 	// (1) Run validate before create data
 	err := service.Validate.Struct(request)
-	// (2) If error, handle with helper
-	helper.PanicErr(err)
-
-	// (3) Create transactional database
-	tx, err := service.DB.Begin()
-	// (4) Handle if create transaction error
-	helper.PanicErr(err)
-	// (5) Run this process in the end all operation with defer, and check process transaction Commit or Rollback transaction
-	defer helper.CommitOrRollback(tx)
+	// (2) If error, return validation error
+	if err != nil {
+		return web.CategoryResponse{}, exception.NewValidationError(err)
+	}
 
-	// (6) Create new object category
+	// (3) Create new object category, created and updated by the authenticated principal
+	now, principal := auditInfo(ctx)
 	category := domain.Category{
-		// Set name from request
-		Name: request.Name,
+		// Set name and parent from request
+		Name:      request.Name,
+		ParentId:  request.ParentId,
+		CreatedAt: now,
+		UpdatedAt: now,
+		CreatedBy: principal,
+		UpdatedBy: principal,
 	}
 
-	// (7) Save transaction with use Repository
-	category = service.CategoryRepository.Save(ctx, tx, category)
+	// (4) Save category with use Repository in one transaction, name must not be used by other category
+	err = service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		if err := service.checkNameAvailable(ctx, tx, category); err != nil {
+			return err
+		}
+		if err := service.checkParent(ctx, tx, category); err != nil {
+			return err
+		}
+
+		category, err = service.CategoryRepository.Save(ctx, tx, category)
+		return err
+	})
+	// (5) Return error if transaction failed
+	if err != nil {
+		return web.CategoryResponse{}, err
+	}
 
-	// (8) Return after success
-	return helper.ToCategoryResponse(category)
+	// (6) Return after success, and publish the created category
+	service.publish(categoryChange{CategoryEventCreated, category})
+	return helper.ToCategoryResponse(category), nil
 }
 
 // Function service for proses update category
-func (service *CategoryServiceImpl) Update(ctx context.Context, request web.CategoryUpdateRequest) web.CategoryResponse {
+func (service *CategoryServiceImpl) Update(ctx context.Context, request web.CategoryUpdateRequest) (web.CategoryResponse, error) {
 	// (1) Run validate before create data
 	err := service.Validate.Struct(request)
-	// (2) If error, handle with helper
-	helper.PanicErr(err)
+	// (2) If error, return validation error
+	if err != nil {
+		return web.CategoryResponse{}, exception.NewValidationError(err)
+	}
+
+	var category domain.Category
+	err = service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (3) Find category in dataabase, return error not found if category is not available
+		category, err = service.CategoryRepository.FindById(ctx, tx, request.Id)
+		if err != nil {
+			return err
+		}
+
+		// (4) Category is only updated when the version is requested
+		if err := checkIfMatch(category, request.IfMatch); err != nil {
+			return err
+		}
+
+		// (5) If no error, set request name and parent to object category, name must not be used by other category
+		category.Name = request.Name
+		category.ParentId = request.ParentId
+		category.UpdatedAt, category.UpdatedBy = auditInfo(ctx)
+		if err := service.checkNameAvailable(ctx, tx, category); err != nil {
+			return err
+		}
+		if err := service.checkParent(ctx, tx, category); err != nil {
+			return err
+		}
+
+		// (6) Update category with use Repository
+		category, err = service.CategoryRepository.Update(ctx, tx, category)
+		return err
+	})
+	// (7) Return error if transaction failed
+	if err != nil {
+		return web.CategoryResponse{}, err
+	}
+
+	// (8) Return response with helper, and publish the updated category
+	service.publish(categoryChange{CategoryEventUpdated, category})
+	return helper.ToCategoryResponse(category), nil
+}
+
+// Function service for process patch category, patch is applied to json of CategoryUpdateRequest
+// and validated with the same rule as update
+func (service *CategoryServiceImpl) Patch(ctx context.Context, categoryId int, patch helper.Patch, ifMatch []int) (web.CategoryResponse, error) {
+	var category domain.Category
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Find category in database, return error not found if category is not available
+		var err error
+		category, err = service.CategoryRepository.FindById(ctx, tx, categoryId)
+		if err != nil {
+			return err
+		}
+
+		// (2) Category is only patched when the version is requested
+		if err := checkIfMatch(category, ifMatch); err != nil {
+			return err
+		}
+
+		// (3) Apply patch to category, patch that can not be applied is conflict with current category
+		document, err := json.Marshal(web.CategoryUpdateRequest{Id: category.Id, Name: category.Name, ParentId: category.ParentId})
+		if err != nil {
+			return err
+		}
+		document, err = patch.Apply(document)
+		var patchError helper.PatchError
+		if errors.As(err, &patchError) {
+			return exception.NewConflictError(patchError.Message.Key, patchError.Message.Args...)
+		}
+		if err != nil {
+			return err
+		}
+
+		// (4) Decode and validate the patched category
+		request := web.CategoryUpdateRequest{}
+		if err := helper.DecodeJSON(document, &request); err != nil {
+			return err
+		}
+		if request.Id != category.Id {
+			return exception.NewFieldValidationError("id", "eq", strconv.Itoa(category.Id), "{0} can not be changed", "id")
+		}
+		if err := service.Validate.Struct(request); err != nil {
+			return exception.NewValidationError(err)
+		}
+
+		// (5) Set patched name and parent to object category, name must not be used by other category
+		category.Name = request.Name
+		category.ParentId = request.ParentId
+		category.UpdatedAt, category.UpdatedBy = auditInfo(ctx)
+		if err := service.checkNameAvailable(ctx, tx, category); err != nil {
+			return err
+		}
+		if err := service.checkParent(ctx, tx, category); err != nil {
+			return err
+		}
+
+		// (6) Update category with use Repository
+		category, err = service.CategoryRepository.Update(ctx, tx, category)
+		return err
+	})
+	// (7) Return error if transaction failed
+	if err != nil {
+		return web.CategoryResponse{}, err
+	}
+
+	// (8) Return response with helper, and publish the updated category
+	service.publish(categoryChange{CategoryEventUpdated, category})
+	return helper.ToCategoryResponse(category), nil
+}
+
+// Function service for process delete category, category is soft deleted so it can be restored
+func (service *CategoryServiceImpl) Delete(ctx context.Context, request web.CategoryDeleteRequest) error {
+	// (1) Run validate before delete data
+	if err := service.Validate.Struct(request); err != nil {
+		return exception.NewValidationError(err)
+	}
+
+	var changes []categoryChange
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (2) Find category by id with use Repository, return error not found if category is not available
+		category, err := service.CategoryRepository.FindById(ctx, tx, request.Id)
+		if err != nil {
+			return err
+		}
+
+		// (3) Category is only deleted when the version is requested
+		if err := checkIfMatch(category, request.IfMatch); err != nil {
+			return err
+		}
+
+		// (4) Reject, delete or move children of category
+		changes, err = service.deleteChildren(ctx, tx, category, request.Children)
+		if err != nil {
+			return err
+		}
+
+		// (5) If no error, Delete category, deleted is also an update for sync with updated_since
+		category.UpdatedAt, category.UpdatedBy = auditInfo(ctx)
+		category.DeletedAt = category.UpdatedAt
+		if err := service.CategoryRepository.Delete(ctx, tx, category); err != nil {
+			return err
+		}
+		category.Version++
+		changes = append(changes, categoryChange{CategoryEventDeleted, category})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// (6) Publish the deleted category and its changed children
+	service.publish(changes...)
+	return nil
+}
+
+// Function service for process restore soft deleted category
+func (service *CategoryServiceImpl) Restore(ctx context.Context, categoryId int) (web.CategoryResponse, error) {
+	var category domain.Category
+	restored := false
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Find category include deleted category, return error not found if category is not available
+		var err error
+		category, err = service.CategoryRepository.FindByIdWithDeleted(ctx, tx, categoryId)
+		if err != nil {
+			return err
+		}
+
+		// (2) Category that not deleted is returned as it is
+		if !category.Deleted() {
+			return nil
+		}
+
+		// (3) Name may be used by other category after deleted, and parent must be restored first
+		if err := service.checkNameAvailable(ctx, tx, category); err != nil {
+			return err
+		}
+		if err := service.checkParentRestored(ctx, tx, category); err != nil {
+			return err
+		}
+
+		// (4) Clear deleted time and update category
+		category.DeletedAt = time.Time{}
+		category.UpdatedAt, category.UpdatedBy = auditInfo(ctx)
+		category, err = service.CategoryRepository.Update(ctx, tx, category)
+		restored = err == nil
+		return err
+	})
+	if err != nil {
+		return web.CategoryResponse{}, err
+	}
+
+	// Restored category is published as updated, because it is available again
+	if restored {
+		service.publish(categoryChange{CategoryEventUpdated, category})
+	}
+	return helper.ToCategoryResponse(category), nil
+}
 
-	// (3) Create transactional database
-	tx, err := service.DB.Begin()
-	// (4) Handle if create transaction error
-	helper.PanicErr(err)
-	// (5) Run this process in the end all operation with defer, and check process transaction Commit or Rollback transaction
-	defer helper.CommitOrRollback(tx)
+// Function service for process find category by id
+func (service *CategoryServiceImpl) FindById(ctx context.Context, categoryId int) (web.CategoryResponse, error) {
+	var category domain.Category
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Find category by id with use Repository, return error not found if category is not available
+		var err error
+		category, err = service.CategoryRepository.FindById(ctx, tx, categoryId)
+		return err
+	})
+	// (2) Return error if transaction failed
+	if err != nil {
+		return web.CategoryResponse{}, err
+	}
 
-	// (6) Find category in dataabase
-	category, err := service.CategoryRepository.FindById(ctx, tx, request.Id)
+	// (3) If no error, Return category
+	return helper.ToCategoryResponse(category), nil
+}
 
-	// (7) If error / category not found handle error with exception not found
+// Function service for process find all category with pagination
+func (service *CategoryServiceImpl) FindAll(ctx context.Context, request web.CategoryListRequest) (web.CategoryPageResponse, error) {
+	// (1) Run validate before find data
+	err := service.Validate.Struct(request)
+	// (2) If error, return validation error
 	if err != nil {
-		panic(exception.NewNotFoundError(err.Error()))
+		return web.CategoryPageResponse{}, exception.NewValidationError(err)
 	}
 
-	// (8) If no error, set request name to object category
-	category.Name = request.Name
+	// (3) Only one pagination mode can be used
+	cursor := "after"
+	if request.Before > 0 {
+		cursor = "before"
+	}
+	if (request.Page > 0 && (request.After > 0 || request.Before > 0)) || (request.After > 0 && request.Before > 0) {
+		return web.CategoryPageResponse{}, exception.NewFieldValidationError(cursor, "excluded_with", "page after before", "use only one of page, after or before")
+	}
 
-	// (9) Update category with use Repository
-	category = service.CategoryRepository.Update(ctx, tx, category)
+	// (4) Cursor pagination only work when categories ordered by id ascending
+	if (request.After > 0 || request.Before > 0) && ((request.Sort != "" && request.Sort != "id") || request.Order == "desc") {
+		return web.CategoryPageResponse{}, exception.NewFieldValidationError(cursor, "excluded_with", "sort order", "after and before only support sort by id ascending")
+	}
+
+	// (5) Limit page size by server
+	size := request.Size
+	if size == 0 {
+		size = DefaultPageSize
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+
+	// (6) Offset of page must not overflow
+	if maxPage := math.MaxInt / size; request.Page > maxPage {
+		return web.CategoryPageResponse{}, exception.NewFieldValidationError("page", "max", strconv.Itoa(maxPage), "{0} must be {1} or less", "page", strconv.Itoa(maxPage))
+	}
 
-	// (10) Return response with helper
-	return helper.ToCategoryResponse(category)
+	// (7) Create filter from request
+	filter := domain.CategoryFilter{
+		Query:       request.Q,
+		MatchPrefix: request.Match == "prefix",
+		Sort:        request.Sort,
+		Desc:        request.Order == "desc",
+		WithDeleted: request.IncludeDeleted,
+	}
+	if request.UpdatedSince != nil {
+		filter.UpdatedSince = request.UpdatedSince.UTC()
+	}
+
+	page := web.CategoryPageResponse{Size: size}
+	var categories []domain.Category
+	err = service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (8) Get total all categories match with filter
+		var err error
+		page.Total, err = service.CategoryRepository.Count(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+
+		// (9) Get categories based on pagination mode
+		switch {
+		case request.After > 0:
+			categories, err = service.findAfter(ctx, tx, filter, request.After, size, &page)
+		case request.Before > 0:
+			categories, err = service.findBefore(ctx, tx, filter, request.Before, size, &page)
+		default:
+			page.Page = request.Page
+			if page.Page == 0 {
+				page.Page = 1
+			}
+			filter.Limit = size
+			filter.Offset = (page.Page - 1) * size
+			categories, err = service.CategoryRepository.FindAll(ctx, tx, filter)
+			page.HasNext = page.Page*size < page.Total
+			page.HasPrev = page.Page > 1
+		}
+
+		return err
+	})
+	// (10) Return error if transaction failed
+	if err != nil {
+		return web.CategoryPageResponse{}, err
+	}
+
+	// (11) Return with helper ToCategoryResponses
+	page.Categories = helper.ToCategoryResponses(categories)
+	return page, nil
 }
 
-// Function service for process delete category
-func (service *CategoryServiceImpl) Delete(ctx context.Context, categoryId int) {
-	// (1) Create transactional database
-	tx, err := service.DB.Begin()
-	// (2) Handle if create transaction error
-	helper.PanicErr(err)
-	// (3) Run this process in the end all operation with defer, and check process transaction Commit or Rollback transaction
-	defer helper.CommitOrRollback(tx)
+// Function service for process permanently delete category that soft deleted before retention window
+func (service *CategoryServiceImpl) Purge(ctx context.Context, request web.CategoryPurgeRequest) (web.CategoryPurgeResponse, error) {
+	// (1) Run validate before purge data
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return web.CategoryPurgeResponse{}, exception.NewValidationError(err)
+	}
+
+	// (2) Only category deleted before retention window is purged
+	retention := DefaultPurgeRetention
+	if request.Retention != nil {
+		retention = *request.Retention
+	}
+	now, _ := auditInfo(ctx)
+	purgeResponse := web.CategoryPurgeResponse{DeletedBefore: now.Add(-retention)}
 
-	// (2) Find category by id with use Repository
-	category, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
+	// (3) Permanently delete category with use Repository
+	err = service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		purgeResponse.Purged, err = service.CategoryRepository.Purge(ctx, tx, purgeResponse.DeletedBefore)
+		return err
+	})
+	if err != nil {
+		return web.CategoryPurgeResponse{}, err
+	}
 
-	//  (3) If error / category not found handle error with exception
+	return purgeResponse, nil
+}
+
+// Function for check whether name of category is not used by other category, case insensitive
+func (service *CategoryServiceImpl) checkNameAvailable(ctx context.Context, tx repository.Tx, category domain.Category) error {
+	var notFoundError exception.NotFoundError
+	existing, err := service.CategoryRepository.FindByName(ctx, tx, category.Name)
+	if errors.As(err, &notFoundError) {
+		return nil
+	}
 	if err != nil {
-		panic(exception.NewNotFoundError(err.Error()))
+		return err
 	}
 
-	// (4) If no error, Delete category
-	service.CategoryRepository.Delete(ctx, tx, category)
+	if existing.Id == category.Id {
+		return nil
+	}
+
+	return exception.NewDuplicateError(existing.Id, "category name {0} is already used by category {1}", category.Name, strconv.Itoa(existing.Id))
 }
 
-// Function service for process delete category
-func (service *CategoryServiceImpl) FindById(ctx context.Context, categoryId int) web.CategoryResponse {
-	// (1) Create transactional database
-	tx, err := service.DB.Begin()
-	// (2) Handle if create transaction error
-	helper.PanicErr(err)
-	// (3) Run this process in the end all operation with defer, and check process transaction Commit or Rollback transaction
-	defer helper.CommitOrRollback(tx)
+// Function for check whether version of category is one of version from header If-Match
+func checkIfMatch(category domain.Category, ifMatch []int) error {
+	if len(ifMatch) == 0 {
+		return nil
+	}
+
+	for _, version := range ifMatch {
+		if version == category.Version {
+			return nil
+		}
+	}
 
-	// (2) Find category by id with use Repository
-	category, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
+	return exception.NewPreconditionFailedError("category has been modified, get the latest version and try again")
+}
 
-	//  (3) If error / category not found handle error with exception
+// Function for find categories after cursor, take one more data for check next page
+func (service *CategoryServiceImpl) findAfter(ctx context.Context, tx repository.Tx, filter domain.CategoryFilter, after int, size int, page *web.CategoryPageResponse) ([]domain.Category, error) {
+	filter.Limit = size + 1
+	filter.AfterId = after
+	categories, err := service.CategoryRepository.FindAll(ctx, tx, filter)
 	if err != nil {
-		panic(exception.NewNotFoundError(err.Error()))
+		return nil, err
 	}
 
-	// (4) If no error, Return category
-	return helper.ToCategoryResponse(category)
+	if len(categories) > size {
+		page.HasNext = true
+		categories = categories[:size]
+	}
+
+	page.PrevBefore = after + 1
+	if len(categories) > 0 {
+		page.NextAfter = categories[len(categories)-1].Id
+		page.PrevBefore = categories[0].Id
+	}
+
+	filter.AfterId, filter.BeforeId = 0, page.PrevBefore
+	page.HasPrev, err = service.exists(ctx, tx, filter)
+	return categories, err
 }
 
-// Function service for process delete category
-func (service *CategoryServiceImpl) FindAll(ctx context.Context) []web.CategoryResponse {
-	// (1) Create transactional database
-	tx, err := service.DB.Begin()
-	// (2) Handle if create transaction error
-	helper.PanicErr(err)
-	// (3) Run this process in the end all operation with defer, and check process transaction Commit or Rollback transaction
-	defer helper.CommitOrRollback(tx)
+// Function for find categories before cursor, take one more data for check previous page
+func (service *CategoryServiceImpl) findBefore(ctx context.Context, tx repository.Tx, filter domain.CategoryFilter, before int, size int, page *web.CategoryPageResponse) ([]domain.Category, error) {
+	filter.Limit = size + 1
+	filter.BeforeId = before
+	categories, err := service.CategoryRepository.FindAll(ctx, tx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(categories) > size {
+		page.HasPrev = true
+		categories = categories[1:]
+	}
+
+	page.NextAfter = before - 1
+	if len(categories) > 0 {
+		page.NextAfter = categories[len(categories)-1].Id
+		page.PrevBefore = categories[0].Id
+	}
 
-	// (2) Get all categories
-	categories := service.CategoryRepository.FindAll(ctx, tx)
+	filter.AfterId, filter.BeforeId = page.NextAfter, 0
+	page.HasNext, err = service.exists(ctx, tx, filter)
+	return categories, err
+}
+
+// Function for check whether any category match with filter
+func (service *CategoryServiceImpl) exists(ctx context.Context, tx repository.Tx, filter domain.CategoryFilter) (bool, error) {
+	filter.Limit = 1
+	categories, err := service.CategoryRepository.FindAll(ctx, tx, filter)
+	return len(categories) > 0, err
+}
 
-	// (3)  Return with helper ToCategoryResponses
-	return helper.ToCategoryResponses(categories)
+// Function for get time now and name of authenticated principal for audit,
+// time is truncated to microsecond same as precision of database
+func auditInfo(ctx context.Context) (time.Time, string) {
+	principal, _ := helper.PrincipalFromContext(ctx)
+	return time.Now().UTC().Truncate(time.Microsecond), principal.Name
 }
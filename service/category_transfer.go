@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/repository"
+)
+
+// Max row of imported file
+const MaxImportRows = 10000
+
+// Total category read in one transaction of export
+const exportBatchSize = 500
+
+// Function service for process export all category, category is sent to write one at a time
+// ordered by id. Category is read in batch after id of the last category, each batch in its own
+// short transaction, so slow client not hold transaction or lock while category is written.
+func (service *CategoryServiceImpl) Export(ctx context.Context, write func(category web.CategoryResponse) error) error {
+	lastId := 0
+	for {
+		// (1) Read the next batch of category
+		var categories []domain.Category
+		err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+			var err error
+			categories, err = service.CategoryRepository.FindAll(ctx, tx, domain.CategoryFilter{AfterId: lastId, Limit: exportBatchSize})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		// (2) Write category outside of transaction
+		for _, category := range categories {
+			if err := write(helper.ToCategoryResponse(category)); err != nil {
+				return err
+			}
+		}
+
+		// (3) Batch that not full is the last batch
+		if len(categories) < exportBatchSize {
+			return nil
+		}
+		lastId = categories[len(categories)-1].Id
+	}
+}
+
+// Function service for process import category from file in one transaction, row with id update
+// the category and row without id create new category. Each row is validated with the same rule
+// as create or update, and see change of the previous row.
+func (service *CategoryServiceImpl) Import(ctx context.Context, request web.CategoryImportRequest) ([]CategoryBulkResult, error) {
+	// (1) Run validate for total row
+	if err := service.Validate.Struct(request); err != nil {
+		return nil, exception.NewValidationError(err)
+	}
+
+	scope := categoryScope{}
+	for _, row := range request.Rows {
+		scope.touch(row.Id, row.ParentId, row.Name)
+	}
+	return service.bulk(ctx, scope, len(request.Rows), request.BestEffort, request.DryRun, func(snapshot *categorySnapshot, index int) (int, error) {
+		// (2) Row that can not be read is failed
+		row := request.Rows[index]
+		if row.Error != nil {
+			return 0, row.Error
+		}
+
+		// (3) Create or update category in snapshot
+		if row.Id == 0 {
+			return service.createItem(ctx, snapshot, web.CategoryCreateRequest{Name: row.Name, ParentId: row.ParentId})
+		}
+		return service.updateItem(ctx, snapshot, web.CategoryUpdateRequest{Id: row.Id, Name: row.Name, ParentId: row.ParentId})
+	})
+}
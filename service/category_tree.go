@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/repository"
+)
+
+// Behavior when delete category that has children
+const (
+	DeleteChildrenReject   = "reject"   // Category that has children can not be deleted
+	DeleteChildrenCascade  = "cascade"  // All children and their descendant is deleted too
+	DeleteChildrenReparent = "reparent" // Children is moved to parent of deleted category
+)
+
+// Max depth of category when not configured, root category has depth 1
+const DefaultMaxDepth = 5
+
+// Config of category hierarchy
+type CategoryTreeConfig struct {
+	MaxDepth       int    // Max depth of category, root category has depth 1
+	DeleteChildren string // Default behavior when delete category that has children
+}
+
+// Function service for process find children of category
+func (service *CategoryServiceImpl) FindChildren(ctx context.Context, categoryId int) ([]web.CategoryResponse, error) {
+	var children []domain.Category
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Find category by id, return error not found if category is not available
+		if _, err := service.CategoryRepository.FindById(ctx, tx, categoryId); err != nil {
+			return err
+		}
+
+		// (2) Find children of category
+		var err error
+		children, err = service.CategoryRepository.FindAll(ctx, tx, domain.CategoryFilter{ParentId: categoryId})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ToCategoryResponses(children), nil
+}
+
+// Function service for process find ancestors of category
+func (service *CategoryServiceImpl) FindAncestors(ctx context.Context, categoryId int) ([]web.CategoryResponse, error) {
+	var lineage []domain.Category
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Find category and its ancestors, return error not found if category is not available
+		var err error
+		lineage, err = service.lineage(ctx, tx, categoryId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// (2) Order ancestors from root category, without the category itself
+	ancestors := []web.CategoryResponse{}
+	for i := len(lineage) - 1; i > 0; i-- {
+		ancestors = append(ancestors, helper.ToCategoryResponse(lineage[i]))
+	}
+
+	return ancestors, nil
+}
+
+// Function service for process find all category as tree
+func (service *CategoryServiceImpl) FindTree(ctx context.Context) ([]web.CategoryTreeResponse, error) {
+	var categories []domain.Category
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		var err error
+		categories, err = service.CategoryRepository.FindAll(ctx, tx, domain.CategoryFilter{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ToCategoryTreeResponses(categories), nil
+}
+
+// Function for find category and its ancestors, ordered from the category to root category
+func (service *CategoryServiceImpl) lineage(ctx context.Context, tx repository.Tx, categoryId int) ([]domain.Category, error) {
+	// (1) Find category and all of its ancestor with one query
+	ancestors, err := service.CategoryRepository.FindAncestors(ctx, tx, []int{categoryId})
+	if err != nil {
+		return nil, err
+	}
+	categories := map[int]domain.Category{}
+	for _, category := range ancestors {
+		categories[category.Id] = category
+	}
+
+	// (2) Walk parent from the category, category or parent that not available is not found.
+	// Stop when the same category is found again, so invalid data not cause infinite loop
+	var lineage []domain.Category
+	visited := map[int]bool{}
+	for categoryId > 0 && !visited[categoryId] {
+		category, ok := categories[categoryId]
+		if !ok {
+			return nil, exception.NewNotFoundError("category is not found")
+		}
+
+		visited[categoryId] = true
+		lineage = append(lineage, category)
+		categoryId = category.ParentId
+	}
+
+	return lineage, nil
+}
+
+// Function for find all descendant of category, ordered by level
+func (service *CategoryServiceImpl) descendants(ctx context.Context, tx repository.Tx, categoryId int) ([][]domain.Category, error) {
+	// (1) Find all descendant with one query
+	categories, err := service.CategoryRepository.FindDescendants(ctx, tx, []int{categoryId})
+	if err != nil {
+		return nil, err
+	}
+
+	// (2) Group descendant by level in memory
+	return descendantLevels(categories, categoryId, service.TreeConfig.MaxDepth), nil
+}
+
+// Function for group active category that descendant of category by level, the first level is children.
+// Level deeper than one more than max depth is not grouped, so invalid data with cycle not cause infinite loop
+func descendantLevels(categories []domain.Category, categoryId int, maxDepth int) [][]domain.Category {
+	var levels [][]domain.Category
+	parents := map[int]bool{categoryId: true}
+
+	for len(levels) <= maxDepth {
+		var level []domain.Category
+		children := map[int]bool{}
+		for _, category := range categories {
+			if !category.Deleted() && parents[category.ParentId] {
+				level = append(level, category)
+				children[category.Id] = true
+			}
+		}
+		if len(level) == 0 {
+			break
+		}
+
+		levels = append(levels, level)
+		parents = children
+	}
+
+	return levels
+}
+
+// Function for check whether parent of category is available, not the category itself or its descendant,
+// and the category with its descendant not deeper than max depth
+func (service *CategoryServiceImpl) checkParent(ctx context.Context, tx repository.Tx, category domain.Category) error {
+	if category.ParentId == 0 {
+		return nil
+	}
+
+	// (1) Parent must be available
+	var notFoundError exception.NotFoundError
+	lineage, err := service.lineage(ctx, tx, category.ParentId)
+	if errors.As(err, &notFoundError) {
+		return parentNotFoundError()
+	}
+	if err != nil {
+		return err
+	}
+
+	// (2) Category can not be moved to itself or its descendant
+	for _, ancestor := range lineage {
+		if ancestor.Id == category.Id {
+			return parentCycleError()
+		}
+	}
+
+	// (3) Depth of the deepest descendant is depth of parent, the category and level of descendant
+	depth := len(lineage) + 1
+	if category.Id > 0 {
+		levels, err := service.descendants(ctx, tx, category.Id)
+		if err != nil {
+			return err
+		}
+		depth += len(levels)
+	}
+	if depth > service.TreeConfig.MaxDepth {
+		return maxDepthError(service.TreeConfig.MaxDepth)
+	}
+
+	return nil
+}
+
+// Function for create error when parent of category is not available
+func parentNotFoundError() error {
+	return exception.NewFieldValidationError("parent_id", "exists", "", "{0} must be an existing category", "parent_id")
+}
+
+// Function for create error when parent of category is the category itself or its descendant
+func parentCycleError() error {
+	return exception.NewFieldValidationError("parent_id", "cycle", "", "{0} can not be the category itself or its descendant", "parent_id")
+}
+
+// Function for create error when category or its descendant deeper than max depth
+func maxDepthError(maxDepth int) error {
+	return exception.NewFieldValidationError("parent_id", "max_depth", strconv.Itoa(maxDepth), "category can not be deeper than {0} levels", strconv.Itoa(maxDepth))
+}
+
+// Function for check whether parent of deleted category is available before restored
+func (service *CategoryServiceImpl) checkParentRestored(ctx context.Context, tx repository.Tx, category domain.Category) error {
+	if category.ParentId == 0 {
+		return nil
+	}
+
+	var notFoundError exception.NotFoundError
+	_, err := service.CategoryRepository.FindById(ctx, tx, category.ParentId)
+	if errors.As(err, &notFoundError) {
+		return exception.NewConflictError("parent category {0} is deleted, restore it first", strconv.Itoa(category.ParentId))
+	}
+
+	return err
+}
+
+// Function for reject, delete or move children of category that will be deleted, return the changed children
+func (service *CategoryServiceImpl) deleteChildren(ctx context.Context, tx repository.Tx, category domain.Category, behavior string) ([]categoryChange, error) {
+	if behavior == "" {
+		behavior = service.TreeConfig.DeleteChildren
+	}
+
+	// (1) Find all descendant of category, nothing to do when category has no children
+	levels, err := service.descendants(ctx, tx, category.Id)
+	if err != nil || len(levels) == 0 {
+		return nil, err
+	}
+
+	var changes []categoryChange
+	now, principal := auditInfo(ctx)
+	switch behavior {
+	case DeleteChildrenCascade:
+		// (2) Delete all descendant with the same time as the category
+		for _, level := range levels {
+			for _, descendant := range level {
+				descendant.UpdatedAt, descendant.UpdatedBy, descendant.DeletedAt = now, principal, now
+				if err := service.CategoryRepository.Delete(ctx, tx, descendant); err != nil {
+					return nil, err
+				}
+				descendant.Version++
+				changes = append(changes, categoryChange{CategoryEventDeleted, descendant})
+			}
+		}
+		return changes, nil
+	case DeleteChildrenReparent:
+		// (3) Move children to parent of the category, so depth of descendant is decreased
+		for _, child := range levels[0] {
+			child.ParentId = category.ParentId
+			child.UpdatedAt, child.UpdatedBy = now, principal
+			child, err = service.CategoryRepository.Update(ctx, tx, child)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, categoryChange{CategoryEventUpdated, child})
+		}
+		return changes, nil
+	default:
+		return nil, hasChildrenError()
+	}
+}
+
+// Function for create error when category that has children is deleted with behavior reject
+func hasChildrenError() error {
+	return exception.NewConflictError("category still has children, use children=cascade or children=reparent")
+}
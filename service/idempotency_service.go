@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+)
+
+type IdempotencyService interface {
+	// Reserve save the key for request with the fingerprint, so only one request is processed for the key.
+	// Key that already saved and not expired is returned with false.
+	Reserve(ctx context.Context, principal string, key string, fingerprint string) (domain.IdempotencyKey, bool, error)
+	// Complete save response of request for the key
+	Complete(ctx context.Context, idempotencyKey domain.IdempotencyKey) error
+	// Release delete the key, so request with the key can be processed again
+	Release(ctx context.Context, idempotencyKey domain.IdempotencyKey) error
+}
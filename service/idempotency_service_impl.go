@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/repository"
+)
+
+type IdempotencyServiceImpl struct {
+	IdempotencyKeyRepository repository.IdempotencyKeyRepository // Use repository
+	UnitOfWork               repository.UnitOfWork               // Use unit of work for transaction
+	TTL                      time.Duration                       // How long key and its response is saved
+	// How long key is reserved for request that still processed. Key without response after the lock
+	// timeout can be reserved again, e.g. when server is restarted while request is processed.
+	LockTimeout time.Duration
+}
+
+func NewIdempotencyService(idempotencyKeyRepository repository.IdempotencyKeyRepository, unitOfWork repository.UnitOfWork, ttl time.Duration, lockTimeout time.Duration) IdempotencyService {
+	return &IdempotencyServiceImpl{
+		IdempotencyKeyRepository: idempotencyKeyRepository,
+		UnitOfWork:               unitOfWork,
+		TTL:                      ttl,
+		LockTimeout:              lockTimeout,
+	}
+}
+
+// Function service for process reserve key before request is processed
+func (service *IdempotencyServiceImpl) Reserve(ctx context.Context, principal string, key string, fingerprint string) (domain.IdempotencyKey, bool, error) {
+	idempotencyKey := domain.IdempotencyKey{}
+	reserved := false
+	now := time.Now().UTC()
+
+	err := service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (1) Delete expired key, so expired key can be used again
+		if _, err := service.IdempotencyKeyRepository.DeleteExpired(ctx, tx, now); err != nil {
+			return err
+		}
+
+		// (2) Return key when already saved, key without response after lock timeout is reserved again
+		existing, err := service.IdempotencyKeyRepository.FindByKey(ctx, tx, principal, key)
+		var notFoundError exception.NotFoundError
+		switch {
+		case errors.As(err, &notFoundError):
+		case err == nil && !existing.Completed() && !now.Before(existing.CreatedAt.Add(service.LockTimeout)):
+			if err := service.IdempotencyKeyRepository.Delete(ctx, tx, existing); err != nil {
+				return err
+			}
+		default:
+			idempotencyKey = existing
+			return err
+		}
+
+		// (3) Save new key, response is saved after request is processed
+		idempotencyKey, err = service.IdempotencyKeyRepository.Save(ctx, tx, domain.IdempotencyKey{
+			Key:         key,
+			Principal:   principal,
+			Fingerprint: fingerprint,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(service.TTL),
+		})
+		reserved = err == nil
+		return err
+	})
+
+	// Key is saved at the same time by other request that still processed
+	var conflictError exception.ConflictError
+	if errors.As(err, &conflictError) {
+		return idempotencyKey, false, exception.NewConflictError("request with the same Idempotency-Key is still processed")
+	}
+
+	return idempotencyKey, reserved, err
+}
+
+// Function service for process save response of request, response is replied until the key expired
+func (service *IdempotencyServiceImpl) Complete(ctx context.Context, idempotencyKey domain.IdempotencyKey) error {
+	return service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		_, err := service.IdempotencyKeyRepository.Update(ctx, tx, idempotencyKey)
+		return err
+	})
+}
+
+// Function service for process release key, e.g. when request failed with server error
+func (service *IdempotencyServiceImpl) Release(ctx context.Context, idempotencyKey domain.IdempotencyKey) error {
+	return service.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		return service.IdempotencyKeyRepository.Delete(ctx, tx, idempotencyKey)
+	})
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/middleware"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for send request with API key
+func doRequestWithKey(router http.Handler, request *http.Request, key string) (*http.Response, map[string]interface{}) {
+	request.Header.Set("X-API-Key", key)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return decodeResponse(recorder)
+}
+
+// Function for issue new API key with admin endpoint
+func issueApiKey(t *testing.T, router http.Handler, name string) (int, string) {
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/keys", strings.NewReader(`{"name": "`+name+`"}`))
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+
+	data := responseBody["data"].(map[string]interface{})
+	return int(data["id"].(float64)), data["key"].(string)
+}
+
+// Function test for issue API key and use it
+func TestIssueApiKey(t *testing.T) {
+	db := setupTestDB()
+	truncateApiKey(db)
+	router := setupRouter(db)
+
+	_, key := issueApiKey(t, router, "mobile")
+	assert.True(t, strings.HasPrefix(key, "ak_"))
+
+	// Issued API key can access category
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	response, _ := doRequestWithKey(router, request, key)
+	assert.Equal(t, 200, response.StatusCode)
+
+	// Issued API key can not manage API key
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/admin/keys", nil)
+	response, _ = doRequestWithKey(router, request, key)
+	assert.Equal(t, 403, response.StatusCode)
+
+	// List API key not show the key, but show the time key is used
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/admin/keys", nil)
+	_, responseBody := doRequest(router, request)
+	apiKey := responseBody["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "mobile", apiKey["name"])
+	assert.Nil(t, apiKey["key"])
+	assert.NotNil(t, apiKey["last_used_at"])
+	assert.Equal(t, key[:9], apiKey["key_prefix"])
+}
+
+// Function test for rotate and revoke API key
+func TestRotateAndRevokeApiKey(t *testing.T) {
+	db := setupTestDB()
+	truncateApiKey(db)
+	router := setupRouter(db)
+
+	id, oldKey := issueApiKey(t, router, "batch")
+
+	// (1) Rotate API key, old key can not be used
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/keys/"+strconv.Itoa(id)+"/rotate", nil)
+	_, responseBody := doRequest(router, request)
+	newKey := responseBody["data"].(map[string]interface{})["key"].(string)
+	assert.NotEqual(t, oldKey, newKey)
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	response, _ := doRequestWithKey(router, request, oldKey)
+	assert.Equal(t, 401, response.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	response, _ = doRequestWithKey(router, request, newKey)
+	assert.Equal(t, 200, response.StatusCode)
+
+	// (2) Revoke API key, key can not be used
+	request = httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/admin/keys/"+strconv.Itoa(id), nil)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	response, _ = doRequestWithKey(router, request, newKey)
+	assert.Equal(t, 401, response.StatusCode)
+
+	// (3) Revoked API key can not be rotated
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/keys/"+strconv.Itoa(id)+"/rotate", nil)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 409, response.StatusCode)
+}
+
+// Store that count how many times API key is authenticated
+type countingApiKeyStore struct {
+	count int
+	keys  map[string]bool
+}
+
+func (store *countingApiKeyStore) Authenticate(ctx context.Context, key string) (domain.ApiKey, error) {
+	store.count++
+	if key == "valid" || store.keys[key] {
+		return domain.ApiKey{Name: key, ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+	return domain.ApiKey{}, exception.NewNotFoundError("api key is not found")
+}
+
+// Function test for cache API key
+func TestApiKeyCache(t *testing.T) {
+	store := &countingApiKeyStore{}
+	cache := middleware.NewApiKeyCache(store, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		apiKey, err := cache.Authenticate(ctx, "valid")
+		assert.Nil(t, err)
+		assert.Equal(t, "valid", apiKey.Name)
+
+		_, err = cache.Authenticate(ctx, "invalid")
+		var notFoundError exception.NotFoundError
+		assert.True(t, errors.As(err, &notFoundError))
+	}
+
+	// Valid key is read from store once, key that not found is not cached
+	assert.Equal(t, 4, store.count)
+}
+
+// Function test for key that created after failed request can be used immediately
+func TestApiKeyCacheNewKey(t *testing.T) {
+	store := &countingApiKeyStore{keys: map[string]bool{}}
+	cache := middleware.NewApiKeyCache(store, time.Minute)
+	ctx := context.Background()
+
+	_, err := cache.Authenticate(ctx, "new")
+	var notFoundError exception.NotFoundError
+	assert.True(t, errors.As(err, &notFoundError))
+
+	store.keys["new"] = true
+	apiKey, err := cache.Authenticate(ctx, "new")
+	assert.Nil(t, err)
+	assert.Equal(t, "new", apiKey.Name)
+}
+
+// Function test for unknown key not remove valid key from cache
+func TestApiKeyCacheUnknownKey(t *testing.T) {
+	store := &countingApiKeyStore{}
+	cache := middleware.NewApiKeyCache(store, time.Minute)
+	ctx := context.Background()
+
+	_, err := cache.Authenticate(ctx, "valid")
+	assert.Nil(t, err)
+
+	for i := 0; i < 20000; i++ {
+		_, err = cache.Authenticate(ctx, "unknown-"+strconv.Itoa(i))
+		assert.NotNil(t, err)
+	}
+
+	_, err = cache.Authenticate(ctx, "valid")
+	assert.Nil(t, err)
+	assert.Equal(t, 20001, store.count)
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/app"
+	"github.com/jabutech/go-crud-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for health route can be accessed without API key
+func TestHealthWithoutApiKey(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, 200, recorder.Result().StatusCode)
+}
+
+// Function test for request with other named API key
+func TestOtherApiKey(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("X-API-Key", "RAHASIA-LAIN")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, 200, recorder.Result().StatusCode)
+}
+
+// Function test for API key from configuration without scope, only granted scope of category
+func TestApiKeyWithoutScope(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/admin/keys", nil)
+	request.Header.Add("X-API-Key", "RAHASIA-LAIN")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, 403, recorder.Result().StatusCode)
+}
+
+// Function test for request without API key
+func TestWithoutApiKey(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, 401, recorder.Result().StatusCode)
+}
+
+// Function test for parse API key from configuration
+func TestParseApiKeys(t *testing.T) {
+	apiKeys, err := app.ParseApiKeys("web:abc, mobile:def\n# comment\nbatch:ghi:jkl\n")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []middleware.ApiKey{
+		{Name: "web", Key: "abc"},
+		{Name: "mobile", Key: "def"},
+		{Name: "batch", Key: "ghi:jkl"},
+	}, apiKeys)
+
+	_, err = app.ParseApiKeys("secret-without-name")
+	assert.NotNil(t, err)
+
+	_, err = app.ParseApiKeys("web:abc,web:def")
+	assert.NotNil(t, err)
+
+	// Scope is separated with space after the key
+	apiKeys, err = app.ParseApiKeys("reader:abc categories:read\nwriter:def  categories:read categories:write")
+	assert.Nil(t, err)
+	assert.Equal(t, []middleware.ApiKey{
+		{Name: "reader", Key: "abc", Scopes: []string{"categories:read"}},
+		{Name: "writer", Key: "def", Scopes: []string{"categories:read", "categories:write"}},
+	}, apiKeys)
+
+	_, err = app.ParseApiKeys("reader:abc categories:unknown")
+	assert.NotNil(t, err)
+}
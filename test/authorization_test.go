@@ -0,0 +1,105 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for send request with bearer token
+func doRequestWithToken(router http.Handler, request *http.Request, token string) (*http.Response, map[string]interface{}) {
+	request.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return decodeResponse(recorder)
+}
+
+// Function test for API key from configuration with read only scope
+func TestReadOnlyApiKey(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Read is allowed
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	response, _ := doRequestWithKey(router, request, "RAHASIA-BACA")
+	assert.Equal(t, 200, response.StatusCode)
+
+	// (2) Create, update and delete is forbidden
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		url := "http://localhost:3000/api/categories"
+		if method != http.MethodPost {
+			url += "/1"
+		}
+		request = httptest.NewRequest(method, url, strings.NewReader(`{"name": "Gadget"}`))
+		response, responseBody := doRequestWithKey(router, request, "RAHASIA-BACA")
+		assert.Equal(t, 403, response.StatusCode, method)
+		assert.Equal(t, 403, int(responseBody["code"].(float64)))
+		assert.Equal(t, "FORBIDDEN", responseBody["status"])
+	}
+
+	// (3) Manage API key is forbidden
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/admin/keys", nil)
+	response, _ = doRequestWithKey(router, request, "RAHASIA-BACA")
+	assert.Equal(t, 403, response.StatusCode)
+}
+
+// Function test for issue API key with scope
+func TestIssueApiKeyWithScope(t *testing.T) {
+	db := setupTestDB()
+	truncateApiKey(db)
+	router := setupRouter(db)
+
+	// (1) Issue API key with read only scope
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/keys", strings.NewReader(`{"name": "report", "scopes": ["categories:read"]}`))
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	data := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"categories:read"}, data["scopes"])
+	key := data["key"].(string)
+
+	// (2) API key only can read
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	response, _ = doRequestWithKey(router, request, key)
+	assert.Equal(t, 200, response.StatusCode)
+
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "Gadget"}`))
+	response, _ = doRequestWithKey(router, request, key)
+	assert.Equal(t, 403, response.StatusCode)
+
+	// (3) Scope admin can not be granted to API key issued at runtime
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/keys", strings.NewReader(`{"name": "root", "scopes": ["admin"]}`))
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+}
+
+// Function test for scope from token claims
+func TestTokenScope(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Claim scp as array
+	claims := validClaims()
+	delete(claims, "scope")
+	claims["scp"] = []string{"categories:read"}
+	token := signHS256(claims, testJwtSecret)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	response, _ := doRequestWithToken(router, request, token)
+	assert.Equal(t, 200, response.StatusCode)
+
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "Gadget"}`))
+	response, _ = doRequestWithToken(router, request, token)
+	assert.Equal(t, 403, response.StatusCode)
+
+	// (2) Token without scope can not access anything
+	delete(claims, "scp")
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	response, _ = doRequestWithToken(router, request, signHS256(claims, testJwtSecret))
+	assert.Equal(t, 403, response.StatusCode)
+}
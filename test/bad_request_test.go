@@ -0,0 +1,104 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for id in path that not a number
+func TestBadRequestId(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		request := httptest.NewRequest(method, "http://localhost:3000/api/categories/abc", strings.NewReader(`{"name": "Gadget"}`))
+		response, responseBody := doRequest(router, request)
+
+		assert.Equal(t, 400, response.StatusCode, method)
+		assert.Equal(t, "BAD REQUEST", responseBody["status"], method)
+		assert.Equal(t, "categoryId must be a number", responseBody["data"], method)
+	}
+
+	request := httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/admin/keys/abc", nil)
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "keyId must be a number", responseBody["data"])
+}
+
+// Function test for request body that can not be decoded
+func TestBadRequestBody(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	tests := map[string]string{
+		``:                                    "request body is required",
+		`{"name": "Gadget"`:                   "request body is not valid JSON",
+		`{"name": 'Gadget'}`:                  "request body is not valid JSON",
+		`{"name": 10}`:                        "field name in request body must be a string",
+		`["Gadget"]`:                          "request body must be an object",
+		`{"name": "Gadget", "color": "red"}`:  "field color in request body is not known",
+		`{"name": "Gadget"} {"name": "Book"}`: "request body must only contain one JSON value",
+		`{"name": "Gadget"}}`:                 "request body must only contain one JSON value",
+	}
+	for body, message := range tests {
+		request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		response, responseBody := doRequest(router, request)
+
+		assert.Equal(t, 400, response.StatusCode, body)
+		assert.Equal(t, message, responseBody["data"], body)
+	}
+
+	// Nothing is created
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	_, responseBody := doRequest(router, request)
+	assert.Equal(t, 0, int(responseBody["paging"].(map[string]interface{})["total"].(float64)))
+}
+
+// Function test for request body with content type that not supported
+func TestUnsupportedMediaType(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`name=Gadget`))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/problem+json")
+	response, responseBody := doRequest(router, request)
+
+	assert.Equal(t, 415, response.StatusCode)
+	assert.Equal(t, "Unsupported Media Type", responseBody["title"])
+	assert.Equal(t, "content type application/x-www-form-urlencoded is not supported, use application/json, application/xml, application/msgpack", responseBody["detail"])
+
+	// Parameter of content type is allowed
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "Gadget"}`))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+}
+
+// Function test for method that not allowed for the path
+func TestMethodNotAllowed(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	// (1) Path parameter that has no handle for the method is written as error response
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/1", strings.NewReader(`{"name": "Gadget"}`))
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 405, response.StatusCode)
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	assert.Equal(t, "GET, PUT, PATCH, DELETE", response.Header.Get("Allow"))
+	assert.Equal(t, "METHOD NOT ALLOWED", responseBody["status"])
+	assert.Equal(t, "method POST is not allowed", responseBody["data"])
+
+	// (2) Path that not registered for the method is written the same way, message is translated
+	request = httptest.NewRequest(http.MethodPatch, "http://localhost:3000/api/categories", nil)
+	request.Header.Set("Accept-Language", "id")
+	request.Header.Set("Accept", "application/problem+json")
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 405, response.StatusCode)
+	assert.Contains(t, response.Header.Get("Allow"), "POST")
+	assert.Equal(t, "Metode Tidak Diizinkan", responseBody["title"])
+	assert.Equal(t, "method PATCH tidak diizinkan", responseBody["detail"])
+}
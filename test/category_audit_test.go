@@ -0,0 +1,91 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for create category with API key, return data of response
+func createCategoryWithKey(t *testing.T, router http.Handler, name string, key string) map[string]interface{} {
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "`+name+`"}`))
+	response, responseBody := doRequestWithKey(router, request, key)
+	assert.Equal(t, 200, response.StatusCode)
+
+	return responseBody["data"].(map[string]interface{})
+}
+
+// Function for parse time in response
+func responseTime(t *testing.T, value interface{}) time.Time {
+	parsed, err := time.Parse(time.RFC3339Nano, value.(string))
+	assert.Nil(t, err)
+	return parsed
+}
+
+// Function test for audit field filled by the authenticated principal
+func TestCategoryAudit(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Create category with API key test
+	before := time.Now().Add(-time.Second)
+	category := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+	assert.Equal(t, "test", category["created_by"])
+	assert.Equal(t, "test", category["updated_by"])
+	assert.True(t, responseTime(t, category["created_at"]).After(before))
+	assert.Equal(t, category["created_at"], category["updated_at"])
+
+	// (2) Update category with other API key
+	id := strconv.Itoa(int(category["id"].(float64)))
+	time.Sleep(2 * time.Millisecond)
+	request := httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+id, strings.NewReader(`{"name": "Book"}`))
+	_, responseBody := doRequestWithKey(router, request, "RAHASIA-LAIN")
+	updated := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, "test", updated["created_by"])
+	assert.Equal(t, "other", updated["updated_by"])
+	assert.Equal(t, category["created_at"], updated["created_at"])
+	assert.True(t, responseTime(t, updated["updated_at"]).After(responseTime(t, updated["created_at"])))
+
+	// (3) Audit field is saved
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+id, nil)
+	_, responseBody = doRequest(router, request)
+	assert.Equal(t, updated, responseBody["data"])
+}
+
+// Function test for list category updated since a time
+func TestListCategoryUpdatedSince(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Create categories, then update one category after since
+	gadget := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+	createCategoryWithKey(t, router, "Book", "RAHASIA")
+	time.Sleep(2 * time.Millisecond)
+	since := time.Now().In(time.FixedZone("WIB", 7*60*60))
+	time.Sleep(2 * time.Millisecond)
+
+	request := httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+strconv.Itoa(int(gadget["id"].(float64))), strings.NewReader(`{"name": "Phone"}`))
+	doRequest(router, request)
+	createCategoryWithKey(t, router, "Shoe", "RAHASIA")
+
+	// (2) Only category updated since the time is returned, ordered by updated time
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?sort=updated_at&order=desc&updated_since="+url.QueryEscape(since.Format(time.RFC3339Nano)), nil)
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, []string{"Shoe", "Phone"}, responseNames(responseBody))
+	assert.Equal(t, 2, int(responseBody["paging"].(map[string]interface{})["total"].(float64)))
+
+	// (3) Time that not RFC 3339 is validation error
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?updated_since=yesterday", nil)
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "updated_since", responseBody["data"].([]interface{})[0].(map[string]interface{})["field"])
+}
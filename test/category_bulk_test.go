@@ -0,0 +1,228 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for send bulk request with method and mode
+func bulkCategory(router http.Handler, method string, mode string, body string) (*http.Response, map[string]interface{}) {
+	request := httptest.NewRequest(method, "http://localhost:3000/api/categories/bulk?mode="+mode, strings.NewReader(body))
+	return doRequest(router, request)
+}
+
+// Function for get result of each item in bulk response
+func bulkItems(responseBody map[string]interface{}) []map[string]interface{} {
+	var items []map[string]interface{}
+	for _, item := range responseBody["data"].(map[string]interface{})["items"].([]interface{}) {
+		items = append(items, item.(map[string]interface{}))
+	}
+	return items
+}
+
+// Function for get all name of category
+func allCategoryNames(router http.Handler) []string {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?sort=id", nil)
+	_, responseBody := doRequest(router, request)
+	return responseNames(responseBody)
+}
+
+// Function test for bulk create category in atomic and best effort mode
+func TestBulkCreateCategory(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	gadget := createChildCategory(t, router, "Gadget", 0)
+
+	// (1) All item is created with parent
+	response, responseBody := bulkCategory(router, http.MethodPost, "", `[
+		{"name": "Phone", "parent_id": `+strconv.Itoa(gadget)+`},
+		{"name": "Book"}
+	]`)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, float64(2), responseBody["data"].(map[string]interface{})["succeeded"])
+	items := bulkItems(responseBody)
+	assert.Equal(t, float64(200), items[0]["status"])
+	assert.Equal(t, float64(gadget), items[0]["data"].(map[string]interface{})["parent_id"])
+	assert.Equal(t, float64(1), items[1]["data"].(map[string]interface{})["version"])
+	assert.Equal(t, []string{"Gadget", "Phone", "Book"}, allCategoryNames(router))
+
+	// (2) In atomic mode no item is created when one of item failed
+	response, responseBody = bulkCategory(router, http.MethodPost, "atomic", `[
+		{"name": "Shoe"},
+		{"name": ""},
+		{"name": "shoe"}
+	]`)
+	assert.Equal(t, 207, response.StatusCode)
+	assert.Equal(t, "MULTI-STATUS", responseBody["status"])
+	items = bulkItems(responseBody)
+	assert.Equal(t, float64(424), items[0]["status"])
+	assert.Equal(t, "item is not applied because other item failed", items[0]["error"])
+	assert.Equal(t, float64(400), items[1]["status"])
+	assert.Equal(t, "name", items[1]["errors"].([]interface{})[0].(map[string]interface{})["field"])
+	assert.Equal(t, float64(409), items[2]["status"])
+	assert.Equal(t, "category name shoe is already used by other item in request", items[2]["error"])
+	assert.Equal(t, []string{"Gadget", "Phone", "Book"}, allCategoryNames(router))
+
+	// (3) In best effort mode valid item is still created
+	response, responseBody = bulkCategory(router, http.MethodPost, "best_effort", `[
+		{"name": "Shoe"},
+		{"name": "BOOK"},
+		{"name": "Pen", "parent_id": 404}
+	]`)
+	assert.Equal(t, 207, response.StatusCode)
+	assert.Equal(t, float64(1), responseBody["data"].(map[string]interface{})["succeeded"])
+	assert.Equal(t, float64(2), responseBody["data"].(map[string]interface{})["failed"])
+	items = bulkItems(responseBody)
+	assert.Equal(t, float64(200), items[0]["status"])
+	assert.Equal(t, float64(409), items[1]["status"])
+	assert.Equal(t, float64(400), items[2]["status"])
+	assert.Equal(t, []string{"Gadget", "Phone", "Book", "Shoe"}, allCategoryNames(router))
+
+	// (4) Mode that not valid, empty array and body that not array is bad request
+	response, _ = bulkCategory(router, http.MethodPost, "maybe", `[{"name": "Bag"}]`)
+	assert.Equal(t, 400, response.StatusCode)
+	response, _ = bulkCategory(router, http.MethodPost, "", `[]`)
+	assert.Equal(t, 400, response.StatusCode)
+	response, _ = bulkCategory(router, http.MethodPost, "", `{"name": "Bag"}`)
+	assert.Equal(t, 400, response.StatusCode)
+}
+
+// Function test for bulk update category, item see change of the previous item
+func TestBulkUpdateCategory(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	gadget := createChildCategory(t, router, "Gadget", 0)
+	book := createChildCategory(t, router, "Book", 0)
+
+	// (1) Name can be swapped, because name of the first item is free after renamed
+	response, responseBody := bulkCategory(router, http.MethodPut, "", `[
+		{"id": `+strconv.Itoa(gadget)+`, "name": "Temporary"},
+		{"id": `+strconv.Itoa(book)+`, "name": "Gadget", "parent_id": `+strconv.Itoa(gadget)+`},
+		{"id": `+strconv.Itoa(gadget)+`, "name": "Book"}
+	]`)
+	assert.Equal(t, 200, response.StatusCode)
+	items := bulkItems(responseBody)
+	assert.Equal(t, "Book", items[2]["data"].(map[string]interface{})["name"])
+	assert.Equal(t, float64(2), items[2]["data"].(map[string]interface{})["version"])
+	assert.Equal(t, float64(gadget), items[1]["data"].(map[string]interface{})["parent_id"])
+
+	// (2) Category that not found and cycle in request is failed item
+	response, responseBody = bulkCategory(router, http.MethodPut, "best_effort", `[
+		{"id": 404, "name": "Pen"},
+		{"id": `+strconv.Itoa(gadget)+`, "name": "Book", "parent_id": `+strconv.Itoa(book)+`}
+	]`)
+	assert.Equal(t, 207, response.StatusCode)
+	items = bulkItems(responseBody)
+	assert.Equal(t, float64(404), items[0]["status"])
+	assert.Equal(t, float64(400), items[1]["status"])
+	assert.Equal(t, "cycle", items[1]["errors"].([]interface{})[0].(map[string]interface{})["rule"])
+
+	// (3) Error of item is translated
+	request := httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/bulk", strings.NewReader(`[{"id": 404, "name": "Pen"}]`))
+	request.Header.Set("Accept-Language", "id")
+	_, responseBody = doRequest(router, request)
+	assert.Equal(t, "kategori tidak ditemukan", bulkItems(responseBody)[0]["error"])
+}
+
+// Function test for bulk delete category with children
+func TestBulkDeleteCategory(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	electronic := createChildCategory(t, router, "Electronic", 0)
+	phone := createChildCategory(t, router, "Phone", electronic)
+	createChildCategory(t, router, "Android", phone)
+	book := createChildCategory(t, router, "Book", 0)
+	novel := createChildCategory(t, router, "Novel", book)
+
+	// (1) Category that has children is rejected by default, so no item is deleted
+	response, responseBody := bulkCategory(router, http.MethodDelete, "", `[
+		{"id": `+strconv.Itoa(novel)+`},
+		{"id": `+strconv.Itoa(electronic)+`}
+	]`)
+	assert.Equal(t, 207, response.StatusCode)
+	items := bulkItems(responseBody)
+	assert.Equal(t, float64(424), items[0]["status"])
+	assert.Equal(t, float64(409), items[1]["status"])
+	assert.Len(t, allCategoryNames(router), 5)
+
+	// (2) Children is deleted or moved based on each item
+	response, responseBody = bulkCategory(router, http.MethodDelete, "", `[
+		{"id": `+strconv.Itoa(electronic)+`, "children": "cascade"},
+		{"id": `+strconv.Itoa(book)+`, "children": "reparent"}
+	]`)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.NotNil(t, bulkItems(responseBody)[0]["data"].(map[string]interface{})["deleted_at"])
+	assert.Equal(t, []string{"Novel"}, allCategoryNames(router))
+
+	// (3) Deleted category is not found in the next request
+	response, responseBody = bulkCategory(router, http.MethodDelete, "", `[{"id": `+strconv.Itoa(phone)+`}]`)
+	assert.Equal(t, 207, response.StatusCode)
+	assert.Equal(t, float64(404), bulkItems(responseBody)[0]["status"])
+
+	// (4) Bulk delete require scope for delete category
+	request := httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/bulk", strings.NewReader(`[{"id": `+strconv.Itoa(novel)+`}]`))
+	response, _ = doRequestWithKey(router, request, "RAHASIA-BACA")
+	assert.Equal(t, 403, response.StatusCode)
+}
+
+// Function test for find category by id, name, ancestor and descendant in storage, used by bulk request
+func TestCategoryScopeStorage(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	ctx := context.Background()
+	unitOfWork := repository.NewUnitOfWork(db)
+
+	// (1) Create tree Gadget > Phone > Case and category Book without parent
+	ids := map[string]int{}
+	err := unitOfWork.Do(ctx, func(tx repository.Tx) error {
+		for _, category := range []domain.Category{{Name: "Gadget"}, {Name: "Phone"}, {Name: "Case"}, {Name: "Book"}} {
+			switch category.Name {
+			case "Phone":
+				category.ParentId = ids["Gadget"]
+			case "Case":
+				category.ParentId = ids["Phone"]
+			}
+			category, err := db.CategoryRepository.Save(ctx, tx, category)
+			if err != nil {
+				return err
+			}
+			ids[category.Name] = category.Id
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+
+	names := func(categories []domain.Category, err error) []string {
+		assert.Nil(t, err)
+		var names []string
+		for _, category := range categories {
+			names = append(names, category.Name)
+		}
+		return names
+	}
+	unitOfWork.Do(ctx, func(tx repository.Tx) error {
+		// (2) Find category with its ancestors, and descendants without the category itself
+		assert.Equal(t, []string{"Gadget", "Phone", "Case", "Book"}, names(db.CategoryRepository.FindAncestors(ctx, tx, []int{ids["Case"], ids["Book"]})))
+		assert.Equal(t, []string{"Phone", "Case"}, names(db.CategoryRepository.FindDescendants(ctx, tx, []int{ids["Gadget"]})))
+		assert.Empty(t, names(db.CategoryRepository.FindDescendants(ctx, tx, nil)))
+
+		// (3) Find category by id and by name case insensitive
+		assert.Equal(t, []string{"Phone", "Book"}, names(db.CategoryRepository.FindAll(ctx, tx, domain.CategoryFilter{Ids: []int{ids["Phone"], ids["Book"]}})))
+		assert.Equal(t, []string{"Gadget", "Case"}, names(db.CategoryRepository.FindAll(ctx, tx, domain.CategoryFilter{Names: []string{"gadget", "CASE", "Pen"}})))
+		return nil
+	})
+}
@@ -7,27 +7,50 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/go-playground/validator"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jabutech/go-crud-restful-api/app"
 	"github.com/jabutech/go-crud-restful-api/controller"
 	"github.com/jabutech/go-crud-restful-api/helper"
 	"github.com/jabutech/go-crud-restful-api/middleware"
+	"github.com/jabutech/go-crud-restful-api/migration"
 	"github.com/jabutech/go-crud-restful-api/model/domain"
 	"github.com/jabutech/go-crud-restful-api/repository"
 	"github.com/jabutech/go-crud-restful-api/service"
 	"github.com/stretchr/testify/assert"
 )
 
-// Function setup for connection to database test
-func setupTestDB() *sql.DB {
+// Storage used by test, contains transaction beginner and all repository
+type testDB struct {
+	repository.TxBeginner
+	CategoryRepository       repository.CategoryRepository
+	ApiKeyRepository         repository.ApiKeyRepository
+	IdempotencyKeyRepository repository.IdempotencyKeyRepository
+	sqlDB                    *sql.DB
+}
+
+// Function setup for connection to database test.
+// Use in memory storage by default, set TEST_DATABASE_URL for run test with MySQL 8.0 or later
+// e.g. TEST_DATABASE_URL=root:root@tcp(localhost:3306)/belajar_restful_golang_test
+func setupTestDB() testDB {
+	dbUrl := os.Getenv("TEST_DATABASE_URL")
+	if dbUrl == "" {
+		memoryDB := repository.NewMemoryDB()
+		return testDB{
+			TxBeginner:               memoryDB,
+			CategoryRepository:       repository.NewCategoryRepositoryMemory(memoryDB),
+			ApiKeyRepository:         repository.NewApiKeyRepositoryMemory(memoryDB),
+			IdempotencyKeyRepository: repository.NewIdempotencyKeyRepositoryMemory(memoryDB),
+		}
+	}
+
 	// (1) Open connection to database
-	db, err := sql.Open("mysql", "root:root@tcp(localhost:3306)/belajar_restful_golang_test")
+	db, err := sql.Open("mysql", dbUrl)
 	// (2) If error handle with helper
 	helper.PanicErr(err)
 
@@ -36,29 +59,66 @@ func setupTestDB() *sql.DB {
 	db.SetConnMaxLifetime(60 * time.Minute)
 	db.SetConnMaxIdleTime(10 * time.Second)
 
-	return db
+	// (3) Create all table with migration
+	migrator, err := migration.NewMigrator(db)
+	helper.PanicErr(err)
+	helper.PanicErr(migrator.Up(context.Background()))
+
+	return testDB{
+		TxBeginner:               repository.NewSqlTxBeginner(db),
+		CategoryRepository:       repository.NewCategoriRepository(),
+		ApiKeyRepository:         repository.NewApiKeyRepository(),
+		IdempotencyKeyRepository: repository.NewIdempotencyKeyRepository(),
+		sqlDB:                    db,
+	}
 }
 
 // Function for handle router endpoint with parameter connetion to db
-func setupRouter(db *sql.DB) http.Handler {
+func setupRouter(db testDB) http.Handler {
 	// (1) Use validator
-	validate := validator.New()
+	validate := app.NewValidator()
 
 	// (2) Endpoint
-	categoryRespository := repository.NewCategoriRepository()
-	categoryService := service.NewCategoryService(categoryRespository, db, validate)
+	unitOfWork := repository.NewUnitOfWork(db)
+	categoryService := service.NewCategoryService(db.CategoryRepository, unitOfWork, validate, service.CategoryTreeConfig{MaxDepth: 3}, nil)
 	categoryController := controller.NewCategoryController(categoryService)
+	apiKeyService := service.NewApiKeyService(db.ApiKeyRepository, unitOfWork, validate)
+	apiKeyController := controller.NewApiKeyController(apiKeyService)
+	idempotencyService := service.NewIdempotencyService(db.IdempotencyKeyRepository, unitOfWork, time.Hour, time.Minute)
 
 	// (3) Use file router
-	router := app.NewRouter(categoryController)
-
-	// (4) Return router with handle middleware
-	return middleware.NewAuthMiddleware(router)
+	router := app.NewRouter(categoryController, apiKeyController)
+
+	// (4) Return router with handle middleware, API key issued at runtime is not cached
+	return middleware.NewAuthMiddleware(middleware.NewContentNegotiationMiddleware(middleware.NewIdempotencyMiddleware(router, idempotencyService)),
+		middleware.NewApiKeyAuthenticator([]middleware.ApiKey{
+			{Name: "test", Key: "RAHASIA", Scopes: domain.AllScopes},
+			{Name: "other", Key: "RAHASIA-LAIN"},
+			{Name: "reader", Key: "RAHASIA-BACA", Scopes: []string{domain.ScopeCategoriesRead}},
+		}, apiKeyService),
+		middleware.NewJwtAuthenticator(testJwtVerifier()),
+	)
 }
 
 // Function for truncate table category
-func truncateCategory(db *sql.DB) {
-	db.Exec("TRUNCATE category")
+func truncateCategory(db testDB) {
+	if db.sqlDB != nil {
+		db.sqlDB.Exec("TRUNCATE category")
+	}
+}
+
+// Function for truncate table idempotency_key
+func truncateIdempotencyKey(db testDB) {
+	if db.sqlDB != nil {
+		db.sqlDB.Exec("TRUNCATE idempotency_key")
+	}
+}
+
+// Function for truncate table api_key
+func truncateApiKey(db testDB) {
+	if db.sqlDB != nil {
+		db.sqlDB.Exec("TRUNCATE api_key")
+	}
 }
 
 // Function test for create category success
@@ -156,11 +216,11 @@ func TestUpdateCategorySuccess(t *testing.T) {
 
 	// (3) Create new data for sample update
 	// (3.1) Create database transactional
-	tx, _ := db.Begin()
+	tx, _ := db.Begin(context.Background())
 	// (3.2) Use repository
-	categoryRepository := repository.NewCategoriRepository()
+	categoryRepository := db.CategoryRepository
 	// (3.3) Create new category
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category, _ := categoryRepository.Save(context.Background(), tx, domain.Category{
 		Name: "Gadget",
 	})
 	// (3.4) Commit transaction
@@ -215,11 +275,11 @@ func TestUpdateCategoryFailed(t *testing.T) {
 
 	// (3) Create new data for sample update
 	// (3.1) Create database transactional
-	tx, _ := db.Begin()
+	tx, _ := db.Begin(context.Background())
 	// (3.2) Use repository
-	categoryRepository := repository.NewCategoriRepository()
+	categoryRepository := db.CategoryRepository
 	// (3.3) Create new category
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category, _ := categoryRepository.Save(context.Background(), tx, domain.Category{
 		Name: "Gadget",
 	})
 	// (3.4) Commit transaction
@@ -270,11 +330,11 @@ func TestGETCategorySuccess(t *testing.T) {
 
 	// (3) Create new data for sample update
 	// (3.1) Create database transactional
-	tx, _ := db.Begin()
+	tx, _ := db.Begin(context.Background())
 	// (3.2) Use repository
-	categoryRepository := repository.NewCategoriRepository()
+	categoryRepository := db.CategoryRepository
 	// (3.3) Create new category
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category, _ := categoryRepository.Save(context.Background(), tx, domain.Category{
 		Name: "Gadget",
 	})
 	// (3.4) Commit transaction
@@ -363,11 +423,11 @@ func TestDeleteCategorySuccess(t *testing.T) {
 
 	// (3) Create new data for sample update
 	// (3.1) Create database transactional
-	tx, _ := db.Begin()
+	tx, _ := db.Begin(context.Background())
 	// (3.2) Use repository
-	categoryRepository := repository.NewCategoriRepository()
+	categoryRepository := db.CategoryRepository
 	// (3.3) Create new category
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category, _ := categoryRepository.Save(context.Background(), tx, domain.Category{
 		Name: "Gadget",
 	})
 	// (3.4) Commit transaction
@@ -457,15 +517,15 @@ func TestGetListCategorySuccess(t *testing.T) {
 
 	// (3) Create new data for sample update
 	// (3.1) Create database transactional
-	tx, _ := db.Begin()
+	tx, _ := db.Begin(context.Background())
 	// (3.2) Use repository
-	categoryRepository := repository.NewCategoriRepository()
+	categoryRepository := db.CategoryRepository
 	// (3.3) Create new category
-	category1 := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category1, _ := categoryRepository.Save(context.Background(), tx, domain.Category{
 		Name: "Gadget",
 	})
 	// (3.4) Create new category
-	category2 := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category2, _ := categoryRepository.Save(context.Background(), tx, domain.Category{
 		Name: "T SHIRT",
 	})
 	// (3.5) Commit transaction
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for ETag of category and header If-None-Match
+func TestCategoryETag(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Create category, the first version is 1
+	category := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+	assert.Equal(t, 1, int(category["version"].(float64)))
+	id := strconv.Itoa(int(category["id"].(float64)))
+
+	// (2) Get category return ETag of version
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+id, nil)
+	response, _ := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `"1"`, response.Header.Get("ETag"))
+
+	// (3) Category that not modified return 304 without body
+	for _, ifNoneMatch := range []string{`"1"`, `W/"1"`, `"0", "1"`, "*"} {
+		request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+id, nil)
+		request.Header.Set("If-None-Match", ifNoneMatch)
+		request.Header.Set("X-API-Key", "RAHASIA")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		assert.Equal(t, 304, recorder.Code, ifNoneMatch)
+		assert.Equal(t, `"1"`, recorder.Header().Get("ETag"))
+		assert.Empty(t, recorder.Body.String())
+	}
+
+	// (4) Update return ETag of the new version
+	request = httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+id, strings.NewReader(`{"name": "Book"}`))
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `"2"`, response.Header.Get("ETag"))
+	assert.Equal(t, 2, int(responseBody["data"].(map[string]interface{})["version"].(float64)))
+
+	// (5) Old version is modified
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+id, nil)
+	request.Header.Set("If-None-Match", `"1"`)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+}
+
+// Function test for header If-Match on update and delete category
+func TestCategoryIfMatch(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	category := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+	id := strconv.Itoa(int(category["id"].(float64)))
+
+	// (1) Update with the current version is success
+	request := httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+id, strings.NewReader(`{"name": "Book"}`))
+	request.Header.Set("If-Match", `"1"`)
+	response, _ := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+
+	// (2) Update with the old version or weak tag is precondition failed
+	for _, ifMatch := range []string{`"1"`, `W/"2"`, `"abc"`} {
+		request = httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+id, strings.NewReader(`{"name": "Shoe"}`))
+		request.Header.Set("If-Match", ifMatch)
+		response, responseBody := doRequest(router, request)
+		assert.Equal(t, 412, response.StatusCode, ifMatch)
+		assert.Equal(t, "PRECONDITION FAILED", responseBody["status"])
+	}
+
+	// (3) Category is not changed by failed update
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+id, nil)
+	_, responseBody := doRequest(router, request)
+	assert.Equal(t, "Book", responseBody["data"].(map[string]interface{})["name"])
+
+	// (4) Delete with the old version is precondition failed
+	request = httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/"+id, nil)
+	request.Header.Set("If-Match", `"1"`)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 412, response.StatusCode)
+
+	// (5) Delete with one of the tag is the current version is success
+	request = httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/"+id, nil)
+	request.Header.Set("If-Match", `"1", "2"`)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+}
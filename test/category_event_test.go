@@ -0,0 +1,158 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for open event stream of server, stream is closed when test is done
+func openCategoryEvents(t *testing.T, server *httptest.Server, lastEventId string) (*http.Response, *bufio.Reader) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/categories/events", nil)
+	request.Header.Add("X-API-Key", "RAHASIA")
+	request.Header.Add("Accept", "text/event-stream")
+	if lastEventId != "" {
+		request.Header.Add("Last-Event-ID", lastEventId)
+	}
+	response, err := http.DefaultClient.Do(request)
+	assert.Nil(t, err)
+	t.Cleanup(func() { response.Body.Close() })
+
+	return response, bufio.NewReader(response.Body)
+}
+
+// Function for read the next event from stream, comment is skipped
+func readCategoryEvent(t *testing.T, reader *bufio.Reader) web.CategoryEvent {
+	event := web.CategoryEvent{}
+	for {
+		line, err := reader.ReadString('\n')
+		assert.Nil(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.Type != "":
+			return event
+		case strings.HasPrefix(line, "data: "):
+			assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		}
+	}
+}
+
+// Function test for replay event after last event id of broker
+func TestCategoryEventBroker(t *testing.T) {
+	broker := service.NewCategoryEventBroker(2)
+	for _, name := range []string{"Gadget", "Book", "Pen"} {
+		broker.Publish(service.CategoryEventCreated, web.CategoryResponse{Name: name})
+	}
+	eventIds := func(events []web.CategoryEvent) []int {
+		var ids []int
+		for _, event := range events {
+			ids = append(ids, event.Id)
+		}
+		return ids
+	}
+
+	// (1) Without last event id nothing is replayed
+	subscription := broker.Subscribe(nil)
+	assert.Empty(t, subscription.Replay)
+	subscription.Close()
+
+	// (2) Event after last event id is replayed
+	lastEventId := 1
+	subscription = broker.Subscribe(&lastEventId)
+	assert.Equal(t, []int{2, 3}, eventIds(subscription.Replay))
+	assert.Equal(t, "Book", subscription.Replay[0].Category.Name)
+	subscription.Close()
+
+	// (3) Reset is replayed first when the next event is removed from buffer or id is unknown
+	for _, lastEventId := range []int{0, 4} {
+		subscription = broker.Subscribe(&lastEventId)
+		assert.Equal(t, []int{1, 2, 3}, eventIds(subscription.Replay), lastEventId)
+		assert.Equal(t, service.CategoryEventReset, subscription.Replay[0].Type)
+		assert.Nil(t, subscription.Replay[0].Category)
+		subscription.Close()
+	}
+
+	// (4) Slow subscriber is closed instead of block publisher
+	subscription = broker.Subscribe(nil)
+	for i := 0; i < 100; i++ {
+		broker.Publish(service.CategoryEventUpdated, web.CategoryResponse{Name: "Pen"})
+	}
+	received := 0
+	for range subscription.Events {
+		received++
+	}
+	assert.Less(t, received, 100)
+	subscription.Close()
+}
+
+// Function test for stream event of created, updated and deleted category
+func TestCategoryEventStream(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+	// Server is closed after the stream is closed
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	// (1) Change of category is streamed after it is committed
+	response, reader := openCategoryEvents(t, server, "")
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	gadget := createChildCategory(t, router, "Gadget", 0)
+	url := "http://localhost:3000/api/categories/" + strconv.Itoa(gadget)
+	request := httptest.NewRequest(http.MethodPut, url, strings.NewReader(`{"name": "Gadget Update"}`))
+	doRequest(router, request)
+	request = httptest.NewRequest(http.MethodDelete, url, nil)
+	doRequest(router, request)
+
+	for i, expected := range []string{service.CategoryEventCreated, service.CategoryEventUpdated, service.CategoryEventDeleted} {
+		event := readCategoryEvent(t, reader)
+		assert.Equal(t, i+1, event.Id)
+		assert.Equal(t, expected, event.Type)
+		assert.Equal(t, gadget, event.Category.Id)
+		assert.Equal(t, i+1, event.Category.Version)
+	}
+
+	// (2) Event after header Last-Event-ID is replayed when client reconnect
+	_, reader = openCategoryEvents(t, server, "1")
+	assert.Equal(t, service.CategoryEventUpdated, readCategoryEvent(t, reader).Type)
+	assert.Equal(t, service.CategoryEventDeleted, readCategoryEvent(t, reader).Type)
+
+	// (3) Failed change is not streamed
+	createChildCategory(t, router, "Book", 0)
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "book"}`))
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 409, response.StatusCode)
+	createChildCategory(t, router, "Pen", 0)
+	assert.Equal(t, "Book", readCategoryEvent(t, reader).Category.Name)
+	assert.Equal(t, "Pen", readCategoryEvent(t, reader).Category.Name)
+}
+
+// Function test for request of event stream that not valid
+func TestCategoryEventStreamBadRequest(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	response, body := negotiateRequest(router, http.MethodGet, "/api/categories/events", "application/json", "", nil)
+	assert.Equal(t, 406, response.StatusCode)
+	assert.Contains(t, string(body), "none of media type in header Accept is supported, use text/event-stream")
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/events", nil)
+	request.Header.Add("Last-Event-ID", "abc")
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "header Last-Event-ID must be a number", responseBody["data"])
+}
@@ -0,0 +1,229 @@
+package test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for send request export category, return the raw body
+func exportCategory(router http.Handler, format string) (*http.Response, string) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/export?format="+format, nil)
+	request.Header.Add("X-API-Key", "RAHASIA")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	return response, string(body)
+}
+
+// Function for send request import category with content type
+func importCategory(router http.Handler, query string, contentType string, body io.Reader) (*http.Response, map[string]interface{}) {
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/import?"+query, body)
+	request.Header.Set("Content-Type", contentType)
+	return doRequest(router, request)
+}
+
+// Function test for export category as csv and ndjson
+func TestExportCategory(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) File without category only contain header
+	response, body := exportCategory(router, "")
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", response.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="categories.csv"`, response.Header.Get("Content-Disposition"))
+	assert.Equal(t, "id,name,parent_id,version,created_at,updated_at,created_by,updated_by\n", body)
+
+	// (2) Each category is one row ordered by id, name with comma is quoted
+	gadget := createChildCategory(t, router, "Gadget, Phone", 0)
+	phone := createChildCategory(t, router, "Phone", gadget)
+	response, body = exportCategory(router, "csv")
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[1], strconv.Itoa(gadget)+`,"Gadget, Phone",,1,`))
+	assert.True(t, strings.HasPrefix(lines[2], strconv.Itoa(phone)+",Phone,"+strconv.Itoa(gadget)+",1,"))
+
+	// (3) Each category is one JSON object for ndjson
+	response, body = exportCategory(router, "ndjson")
+	assert.Equal(t, "application/x-ndjson", response.Header.Get("Content-Type"))
+	lines = strings.Split(strings.TrimSpace(body), "\n")
+	assert.Len(t, lines, 2)
+	var category map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &category))
+	assert.Equal(t, "Phone", category["name"])
+	assert.Equal(t, float64(gadget), category["parent_id"])
+
+	// (4) Format that not supported is bad request
+	response, _ = exportCategory(router, "xlsx")
+	assert.Equal(t, 400, response.StatusCode)
+}
+
+// Function test for export category that read in more than one batch
+func TestExportCategoryBatch(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	categories := seedCategories(db, 1201)
+	router := setupRouter(db)
+
+	// Each category is exported once ordered by id
+	_, body := exportCategory(router, "ndjson")
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	assert.Len(t, lines, len(categories))
+	for _, line := range []int{0, 499, 500, 1200} {
+		var category map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(lines[line]), &category))
+		assert.Equal(t, float64(categories[line].Id), category["id"], line)
+	}
+}
+
+// Function test for export name that read as formula by spreadsheet
+func TestExportCategoryFormula(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	names := []string{"=HYPERLINK(A1)", "+1", "-1", "@SUM(A1)", "\tTab", "'=Quoted", "'Plain", "Plain"}
+	for _, name := range names {
+		request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", nil)
+		body, _ := json.Marshal(map[string]string{"name": name})
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		response, _ := doRequest(router, request)
+		assert.Equal(t, 200, response.StatusCode, name)
+	}
+
+	// (1) Cell that start with formula character is prefixed with '
+	_, file := exportCategory(router, "csv")
+	records, err := csv.NewReader(strings.NewReader(file)).ReadAll()
+	assert.Nil(t, err)
+	var exported []string
+	for _, record := range records[1:] {
+		exported = append(exported, record[1])
+	}
+	assert.Equal(t, []string{"'=HYPERLINK(A1)", "'+1", "'-1", "'@SUM(A1)", "'\tTab", "''=Quoted", "'Plain", "Plain"}, exported)
+
+	// (2) Name of exported file is imported with the original name
+	truncateCategory(db)
+	db = setupTestDB()
+	router = setupRouter(db)
+	importFile := &bytes.Buffer{}
+	writer := csv.NewWriter(importFile)
+	writer.Write([]string{"name"})
+	for _, name := range exported {
+		writer.Write([]string{name})
+	}
+	writer.Flush()
+	response, _ := importCategory(router, "", "text/csv", importFile)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, names, allCategoryNames(router))
+}
+
+// Function test for import category from csv with dry run and row error
+func TestImportCategoryCSV(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	gadget := createChildCategory(t, router, "Gadget", 0)
+	file := "\ufeffName,Parent_Id,Note\n" +
+		"Phone," + strconv.Itoa(gadget) + ",from spreadsheet\n" +
+		"Book,,\n"
+
+	// (1) Dry run only check each row
+	response, responseBody := importCategory(router, "dry_run=true", "text/csv", strings.NewReader(file))
+	assert.Equal(t, 200, response.StatusCode)
+	data := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, true, data["dry_run"])
+	assert.Equal(t, false, data["applied"])
+	assert.Equal(t, float64(2), data["created"])
+	assert.Equal(t, []string{"Gadget"}, allCategoryNames(router))
+
+	// (2) Category is created without dry run
+	response, responseBody = importCategory(router, "", "text/csv", strings.NewReader(file))
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, true, responseBody["data"].(map[string]interface{})["applied"])
+	assert.Equal(t, []string{"Gadget", "Phone", "Book"}, allCategoryNames(router))
+
+	// (3) Row error is reported with line number, no row is written in atomic mode
+	file = "id,name,parent_id\n" +
+		strconv.Itoa(gadget) + ",Electronic,\n" +
+		",,\n" +
+		",Pen,abc\n" +
+		",book,\n"
+	response, responseBody = importCategory(router, "", "text/csv", strings.NewReader(file))
+	assert.Equal(t, 207, response.StatusCode)
+	data = responseBody["data"].(map[string]interface{})
+	assert.Equal(t, false, data["applied"])
+	assert.Equal(t, float64(3), data["failed"])
+	errors := data["errors"].([]interface{})
+	assert.Equal(t, float64(3), errors[0].(map[string]interface{})["line"])
+	assert.Equal(t, "name", errors[0].(map[string]interface{})["errors"].([]interface{})[0].(map[string]interface{})["field"])
+	assert.Equal(t, "parent_id must be a number", errors[1].(map[string]interface{})["error"])
+	assert.Equal(t, float64(409), errors[2].(map[string]interface{})["status"])
+	assert.Equal(t, []string{"Gadget", "Phone", "Book"}, allCategoryNames(router))
+
+	// (4) Valid row is still written in best effort mode, row with id update the category
+	response, responseBody = importCategory(router, "mode=best_effort", "text/csv", strings.NewReader(file))
+	assert.Equal(t, 207, response.StatusCode)
+	assert.Equal(t, float64(1), responseBody["data"].(map[string]interface{})["updated"])
+	assert.Equal(t, []string{"Electronic", "Phone", "Book"}, allCategoryNames(router))
+
+	// (5) File that not valid is rejected
+	for _, file := range []string{"", "title\nPhone\n", "name\n\"Phone\n"} {
+		response, _ = importCategory(router, "", "text/csv", strings.NewReader(file))
+		assert.Equal(t, 400, response.StatusCode, file)
+	}
+	response, _ = importCategory(router, "", "application/json", strings.NewReader(`[{"name": "Phone"}]`))
+	assert.Equal(t, 415, response.StatusCode)
+}
+
+// Function test for import exported ndjson and uploaded file
+func TestImportCategoryNDJSON(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	createChildCategory(t, router, "Gadget", 0)
+
+	// (1) Exported file can be imported again after changed
+	_, body := exportCategory(router, "ndjson")
+	body = strings.Replace(body, `"Gadget"`, `"Electronic"`, 1) + "\n" + `{"name": "Book"}` + "\n"
+	response, responseBody := importCategory(router, "", "application/x-ndjson", strings.NewReader(body))
+	assert.Equal(t, 200, response.StatusCode)
+	data := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), data["updated"])
+	assert.Equal(t, float64(1), data["created"])
+	assert.Equal(t, []string{"Electronic", "Book"}, allCategoryNames(router))
+
+	// (2) Row that not valid JSON is row error
+	response, responseBody = importCategory(router, "", "application/x-ndjson", strings.NewReader(`{"name": "Pen"}`+"\n"+`{"name": 5}`+"\n"+`{name}`))
+	assert.Equal(t, 207, response.StatusCode)
+	errors := responseBody["data"].(map[string]interface{})["errors"].([]interface{})
+	assert.Equal(t, "field name in row must be a string", errors[0].(map[string]interface{})["error"])
+	assert.Equal(t, float64(3), errors[1].(map[string]interface{})["line"])
+
+	// (3) File can be uploaded with multipart form, format is chosen by extension
+	form := &bytes.Buffer{}
+	multipartWriter := multipart.NewWriter(form)
+	multipartWriter.WriteField("note", "categories from spreadsheet")
+	part, _ := multipartWriter.CreateFormFile("file", "categories.csv")
+	part.Write([]byte("name\nPen\n"))
+	multipartWriter.Close()
+
+	response, responseBody = importCategory(router, "", multipartWriter.FormDataContentType(), form)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, float64(1), responseBody["data"].(map[string]interface{})["created"])
+	assert.Equal(t, []string{"Electronic", "Book", "Pen"}, allCategoryNames(router))
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for create sample categories with name Category 1 until Category n
+func seedCategories(db testDB, n int) []domain.Category {
+	ctx := context.Background()
+	tx, _ := db.Begin(ctx)
+
+	var categories []domain.Category
+	for i := 1; i <= n; i++ {
+		category, _ := db.CategoryRepository.Save(ctx, tx, domain.Category{Name: "Category " + strconv.Itoa(i)})
+		categories = append(categories, category)
+	}
+	tx.Commit()
+
+	return categories
+}
+
+// Function for send request to router and decode response body
+func doRequest(router http.Handler, request *http.Request) (*http.Response, map[string]interface{}) {
+	request.Header.Add("X-API-Key", "RAHASIA")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return decodeResponse(recorder)
+}
+
+// Function for decode response body of recorder
+func decodeResponse(recorder *httptest.ResponseRecorder) (*http.Response, map[string]interface{}) {
+	response := recorder.Result()
+
+	body, _ := io.ReadAll(response.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+
+	return response, responseBody
+}
+
+// Function test for list category with page and size
+func TestListCategoryPage(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	categories := seedCategories(db, 5)
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?page=2&size=2", nil)
+	response, responseBody := doRequest(router, request)
+
+	assert.Equal(t, 200, response.StatusCode)
+	data := responseBody["data"].([]interface{})
+	assert.Equal(t, 2, len(data))
+	assert.Equal(t, categories[2].Id, int(data[0].(map[string]interface{})["id"].(float64)))
+
+	paging := responseBody["paging"].(map[string]interface{})
+	assert.Equal(t, 5, int(paging["total"].(float64)))
+	assert.Equal(t, "/api/categories?page=3&size=2", paging["next"])
+	assert.Equal(t, "/api/categories?page=1&size=2", paging["prev"])
+}
+
+// Function test for list category with cursor after
+func TestListCategoryCursor(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	categories := seedCategories(db, 5)
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?after="+strconv.Itoa(categories[3].Id)+"&size=2", nil)
+	response, responseBody := doRequest(router, request)
+
+	assert.Equal(t, 200, response.StatusCode)
+	data := responseBody["data"].([]interface{})
+	assert.Equal(t, 1, len(data))
+	assert.Equal(t, categories[4].Id, int(data[0].(map[string]interface{})["id"].(float64)))
+
+	paging := responseBody["paging"].(map[string]interface{})
+	assert.Nil(t, paging["next"])
+	assert.Equal(t, "/api/categories?before="+strconv.Itoa(categories[4].Id)+"&size=2", paging["prev"])
+
+	// Follow link previous page
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000"+paging["prev"].(string), nil)
+	_, responseBody = doRequest(router, request)
+
+	data = responseBody["data"].([]interface{})
+	assert.Equal(t, 2, len(data))
+	assert.Equal(t, categories[2].Id, int(data[0].(map[string]interface{})["id"].(float64)))
+	assert.Equal(t, categories[3].Id, int(data[1].(map[string]interface{})["id"].(float64)))
+}
+
+// Function test for page size limited by server
+func TestListCategoryMaxPageSize(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	seedCategories(db, 3)
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?size=1000", nil)
+	_, responseBody := doRequest(router, request)
+
+	paging := responseBody["paging"].(map[string]interface{})
+	assert.Equal(t, 100, int(paging["size"].(float64)))
+}
+
+// Function test for invalid pagination parameter
+func TestListCategoryInvalidPaging(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?page=1&after=2", nil)
+	response, _ := doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?page=abc", nil)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+}
+
+// Function test for page that offset of page overflow
+func TestListCategoryMaxPage(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	seedCategories(db, 3)
+	router := setupRouter(db)
+
+	// (1) The last page that offset not overflow is empty
+	maxPage := strconv.Itoa(math.MaxInt / 2)
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?size=2&page="+maxPage, nil)
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Empty(t, responseBody["data"])
+
+	// (2) Page after it is rejected
+	for _, page := range []string{strconv.Itoa(math.MaxInt/2 + 1), strconv.Itoa(math.MaxInt)} {
+		request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?size=2&page="+page, nil)
+		response, responseBody = doRequest(router, request)
+		assert.Equal(t, 400, response.StatusCode, page)
+		assert.Contains(t, responseBody["data"], map[string]interface{}{
+			"field": "page", "rule": "max", "param": maxPage, "message": "page must be " + maxPage + " or less",
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for send request patch category with content type
+func patchCategory(router http.Handler, id string, contentType string, body string) (*http.Response, map[string]interface{}) {
+	request := httptest.NewRequest(http.MethodPatch, "http://localhost:3000/api/categories/"+id, strings.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	return doRequest(router, request)
+}
+
+// Function test for patch category with JSON Merge Patch
+func TestPatchCategoryMergePatch(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	category := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+	id := strconv.Itoa(int(category["id"].(float64)))
+
+	// (1) Patch name of category
+	response, responseBody := patchCategory(router, id, "application/merge-patch+json", `{"name": "Book"}`)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `"2"`, response.Header.Get("ETag"))
+	assert.Equal(t, "Book", responseBody["data"].(map[string]interface{})["name"])
+
+	// (2) Empty patch not change the name
+	response, responseBody = patchCategory(router, id, "application/merge-patch+json", `{}`)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "Book", responseBody["data"].(map[string]interface{})["name"])
+
+	// (3) Removed name is validated with the same rule as update
+	response, responseBody = patchCategory(router, id, "application/merge-patch+json", `{"name": null}`)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "name", responseBody["data"].([]interface{})[0].(map[string]interface{})["field"])
+
+	// (4) Unknown field, wrong type and changed id is bad request
+	for _, body := range []string{`{"color": "red"}`, `{"name": 5}`, `{"id": 99}`} {
+		response, _ = patchCategory(router, id, "application/merge-patch+json", body)
+		assert.Equal(t, 400, response.StatusCode, body)
+	}
+
+	// (5) Category is not changed by failed patch
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+id, nil)
+	_, responseBody = doRequest(router, request)
+	assert.Equal(t, "Book", responseBody["data"].(map[string]interface{})["name"])
+}
+
+// Function test for patch category with JSON Patch
+func TestPatchCategoryJSONPatch(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	category := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+	id := strconv.Itoa(int(category["id"].(float64)))
+
+	// (1) Test then replace name of category
+	response, responseBody := patchCategory(router, id, "application/json-patch+json", `[
+		{"op": "test", "path": "/name", "value": "Gadget"},
+		{"op": "replace", "path": "/name", "value": "Book"}
+	]`)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "Book", responseBody["data"].(map[string]interface{})["name"])
+
+	// (2) Failed test and path that not found is conflict with current category
+	response, responseBody = patchCategory(router, id, "application/json-patch+json", `[{"op": "test", "path": "/name", "value": "Gadget"}]`)
+	assert.Equal(t, 409, response.StatusCode)
+	assert.Equal(t, "test of path /name in JSON Patch failed", responseBody["data"])
+
+	response, _ = patchCategory(router, id, "application/json-patch+json", `[{"op": "remove", "path": "/color"}]`)
+	assert.Equal(t, 409, response.StatusCode)
+
+	// (3) Copy name to the unknown field is bad request
+	response, _ = patchCategory(router, id, "application/json-patch+json", `[{"op": "copy", "from": "/name", "path": "/color"}]`)
+	assert.Equal(t, 400, response.StatusCode)
+
+	// (4) Operation that not valid is bad request
+	for _, body := range []string{`[{"op": "merge", "path": "/name"}]`, `[{"op": "add", "path": "/name"}]`, `[{"op": "move", "path": "/name"}]`, `[{"op": "remove", "path": "name"}]`, `{"op": "remove"}`} {
+		response, _ = patchCategory(router, id, "application/json-patch+json", body)
+		assert.Equal(t, 400, response.StatusCode, body)
+	}
+
+	// (5) Content type that not patch is unsupported
+	response, _ = patchCategory(router, id, "application/json", `{"name": "Shoe"}`)
+	assert.Equal(t, 415, response.StatusCode)
+
+	// (6) Patch with the old version is precondition failed
+	request := httptest.NewRequest(http.MethodPatch, "http://localhost:3000/api/categories/"+id, strings.NewReader(`{"name": "Shoe"}`))
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	request.Header.Set("If-Match", `"1"`)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 412, response.StatusCode)
+}
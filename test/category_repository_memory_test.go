@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for commit transaction in memory storage
+func TestMemoryRepositoryCommit(t *testing.T) {
+	// (1) Use in memory storage
+	db := repository.NewMemoryDB()
+	categoryRepository := repository.NewCategoryRepositoryMemory(db)
+	ctx := context.Background()
+
+	// (2) Create new category and commit
+	tx, _ := db.Begin(ctx)
+	category, _ := categoryRepository.Save(ctx, tx, domain.Category{Name: "Gadget"})
+	assert.Nil(t, tx.Commit())
+
+	// (3) Category must be available in new transaction
+	tx, _ = db.Begin(ctx)
+	defer tx.Rollback()
+	result, err := categoryRepository.FindById(ctx, tx, category.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "Gadget", result.Name)
+}
+
+// Function test for rollback transaction in memory storage
+func TestMemoryRepositoryRollback(t *testing.T) {
+	// (1) Use in memory storage
+	db := repository.NewMemoryDB()
+	categoryRepository := repository.NewCategoryRepositoryMemory(db)
+	ctx := context.Background()
+
+	// (2) Create new category and commit
+	tx, _ := db.Begin(ctx)
+	category, _ := categoryRepository.Save(ctx, tx, domain.Category{Name: "Gadget"})
+	tx.Commit()
+
+	// (3) Update, delete and create category then rollback
+	tx, _ = db.Begin(ctx)
+	categoryRepository.Update(ctx, tx, domain.Category{Id: category.Id, Name: "T SHIRT"})
+	categoryRepository.Save(ctx, tx, domain.Category{Name: "Book"})
+	categoryRepository.Delete(ctx, tx, category)
+	assert.Nil(t, tx.Rollback())
+
+	// (4) All changes must be discarded
+	tx, _ = db.Begin(ctx)
+	defer tx.Rollback()
+	categories, _ := categoryRepository.FindAll(ctx, tx, domain.CategoryFilter{})
+	assert.Equal(t, 1, len(categories))
+	assert.Equal(t, "Gadget", categories[0].Name)
+}
+
+// Function test for transaction that already done
+func TestMemoryRepositoryTxDone(t *testing.T) {
+	db := repository.NewMemoryDB()
+
+	tx, _ := db.Begin(context.Background())
+	assert.Nil(t, tx.Commit())
+	assert.NotNil(t, tx.Commit())
+	assert.NotNil(t, tx.Rollback())
+}
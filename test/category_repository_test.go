@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for get name of each category
+func categoryNames(categories []domain.Category) []string {
+	names := []string{}
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+	return names
+}
+
+// Function for create new category with time and parent
+func newCategory(name string, parentId int) domain.Category {
+	now := time.Now()
+	return domain.Category{Name: name, ParentId: parentId, CreatedAt: now, UpdatedAt: now, CreatedBy: "test", UpdatedBy: "test"}
+}
+
+// Function test for find ancestor and descendant of category in repository.
+// Run with MySQL when TEST_DATABASE_URL is set, so the recursive query is also tested.
+func TestCategoryRepositoryLineage(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	ctx := context.Background()
+	tx, _ := db.Begin(ctx)
+	defer tx.Rollback()
+
+	// (1) Create tree Gadget > Phone > Android, Gadget > Tablet (deleted) and Book > Novel
+	roots, err := db.CategoryRepository.SaveAll(ctx, tx, []domain.Category{newCategory("Gadget", 0), newCategory("Book", 0)})
+	assert.Nil(t, err)
+	gadget, book := roots[0], roots[1]
+	phone, _ := db.CategoryRepository.Save(ctx, tx, newCategory("Phone", gadget.Id))
+	android, _ := db.CategoryRepository.Save(ctx, tx, newCategory("Android", phone.Id))
+	tablet, _ := db.CategoryRepository.Save(ctx, tx, newCategory("Tablet", gadget.Id))
+	novel, _ := db.CategoryRepository.Save(ctx, tx, newCategory("Novel", book.Id))
+
+	tablet.DeletedAt = time.Now()
+	_, err = db.CategoryRepository.UpdateAll(ctx, tx, []domain.Category{tablet})
+	assert.Nil(t, err)
+
+	// (2) Ancestor include the category itself, ordered by id
+	ancestors, err := db.CategoryRepository.FindAncestors(ctx, tx, []int{android.Id})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Gadget", "Phone", "Android"}, categoryNames(ancestors))
+
+	ancestors, err = db.CategoryRepository.FindAncestors(ctx, tx, []int{android.Id, novel.Id, phone.Id})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Gadget", "Book", "Phone", "Android", "Novel"}, categoryNames(ancestors))
+
+	ancestors, err = db.CategoryRepository.FindAncestors(ctx, tx, []int{tablet.Id})
+	assert.Nil(t, err)
+	assert.Empty(t, ancestors)
+
+	// (3) Descendant not include the category itself and deleted category
+	descendants, err := db.CategoryRepository.FindDescendants(ctx, tx, []int{gadget.Id})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Phone", "Android"}, categoryNames(descendants))
+
+	descendants, err = db.CategoryRepository.FindDescendants(ctx, tx, []int{gadget.Id, book.Id})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Phone", "Android", "Novel"}, categoryNames(descendants))
+
+	descendants, err = db.CategoryRepository.FindDescendants(ctx, tx, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, descendants)
+}
+
+// Function test for create and update many category in repository.
+// Run with MySQL when TEST_DATABASE_URL is set, so the multi row query is also tested.
+func TestCategoryRepositorySaveAllAndUpdateAll(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	ctx := context.Background()
+	tx, _ := db.Begin(ctx)
+	defer tx.Rollback()
+
+	// (1) Id of each created category is returned in the same order
+	categories, err := db.CategoryRepository.SaveAll(ctx, tx, []domain.Category{newCategory("Gadget", 0), newCategory("Book", 0), newCategory("Pen", 0)})
+	assert.Nil(t, err)
+	for _, category := range categories {
+		saved, err := db.CategoryRepository.FindById(ctx, tx, category.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, category.Name, saved.Name)
+		assert.Equal(t, 1, saved.Version)
+	}
+
+	// (2) Name that already used is rejected
+	_, err = db.CategoryRepository.SaveAll(ctx, tx, []domain.Category{newCategory("Phone", 0), newCategory("gadget", 0)})
+	var conflictError exception.ConflictError
+	assert.True(t, errors.As(err, &conflictError))
+
+	// (3) Each category is updated with its own value and version is incremented
+	gadget, book, pen := categories[0], categories[1], categories[2]
+	gadget.Name = "Gadgets"
+	book.Name = "Books"
+	book.ParentId = gadget.Id
+	updated, err := db.CategoryRepository.UpdateAll(ctx, tx, []domain.Category{gadget, book})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Gadgets", "Books"}, categoryNames(updated))
+	for _, category := range updated {
+		saved, err := db.CategoryRepository.FindById(ctx, tx, category.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, category.Name, saved.Name)
+		assert.Equal(t, category.ParentId, saved.ParentId)
+		assert.Equal(t, 2, saved.Version)
+	}
+	saved, _ := db.CategoryRepository.FindById(ctx, tx, pen.Id)
+	assert.Equal(t, "Pen", saved.Name)
+	assert.Equal(t, 1, saved.Version)
+
+	// (4) Category with old version is rejected
+	pen.Name = "Pencil"
+	_, err = db.CategoryRepository.UpdateAll(ctx, tx, []domain.Category{pen, book})
+	var preconditionFailedError exception.PreconditionFailedError
+	assert.True(t, errors.As(err, &preconditionFailedError))
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for create categories with name
+func seedCategoryNames(db testDB, names ...string) {
+	ctx := context.Background()
+	tx, _ := db.Begin(ctx)
+	for _, name := range names {
+		db.CategoryRepository.Save(ctx, tx, domain.Category{Name: name})
+	}
+	tx.Commit()
+}
+
+// Function for get name from list response
+func responseNames(responseBody map[string]interface{}) []string {
+	var names []string
+	for _, data := range responseBody["data"].([]interface{}) {
+		names = append(names, data.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+// Function test for search category by name substring and prefix
+func TestListCategorySearch(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	seedCategoryNames(db, "Gadget", "Smart Gadget", "Book", "100% Cotton")
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?q=gadget", nil)
+	_, responseBody := doRequest(router, request)
+	assert.Equal(t, []string{"Gadget", "Smart Gadget"}, responseNames(responseBody))
+	assert.Equal(t, 2, int(responseBody["paging"].(map[string]interface{})["total"].(float64)))
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?q=gadget&match=prefix", nil)
+	_, responseBody = doRequest(router, request)
+	assert.Equal(t, []string{"Gadget"}, responseNames(responseBody))
+
+	// Wildcard character must be searched as plain text
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?q=%25", nil)
+	_, responseBody = doRequest(router, request)
+	assert.Equal(t, []string{"100% Cotton"}, responseNames(responseBody))
+}
+
+// Function test for sort category by name
+func TestListCategorySort(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	seedCategoryNames(db, "Gadget", "book", "T SHIRT")
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?sort=name", nil)
+	_, responseBody := doRequest(router, request)
+	assert.Equal(t, []string{"book", "Gadget", "T SHIRT"}, responseNames(responseBody))
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?sort=id&order=desc", nil)
+	_, responseBody = doRequest(router, request)
+	assert.Equal(t, []string{"T SHIRT", "book", "Gadget"}, responseNames(responseBody))
+}
+
+// Function test for unknown sort field
+func TestListCategoryInvalidSort(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	for _, query := range []string{"sort=password", "order=random", "match=suffix", "sort=name&after=1"} {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?"+query, nil)
+		response, responseBody := doRequest(router, request)
+		assert.Equal(t, 400, response.StatusCode, query)
+		assert.Equal(t, "BAD REQUEST", responseBody["status"], query)
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/app"
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/repository"
+	"github.com/jabutech/go-crud-restful-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for create category service with in memory storage
+func setupService() service.CategoryService {
+	db := repository.NewMemoryDB()
+	return service.NewCategoryService(repository.NewCategoryRepositoryMemory(db), repository.NewUnitOfWork(db), app.NewValidator(), service.CategoryTreeConfig{}, nil)
+}
+
+// Function test for service return not found error
+func TestServiceNotFoundError(t *testing.T) {
+	categoryService := setupService()
+
+	_, err := categoryService.FindById(context.Background(), 404)
+
+	var notFoundError exception.NotFoundError
+	assert.True(t, errors.As(err, &notFoundError))
+	assert.Equal(t, "category is not found", notFoundError.Error())
+}
+
+// Function test for service return validation error
+func TestServiceValidationError(t *testing.T) {
+	categoryService := setupService()
+
+	_, err := categoryService.Create(context.Background(), web.CategoryCreateRequest{Name: ""})
+
+	var validationError exception.ValidationError
+	assert.True(t, errors.As(err, &validationError))
+	assert.Equal(t, 1, len(validationError.Errors))
+	assert.Equal(t, exception.FieldError{Field: "name", Rule: "required", Param: "", Message: helper.NewMessage("{0} is required", "name")}, validationError.Errors[0])
+	assert.Equal(t, "name is required", validationError.Error())
+}
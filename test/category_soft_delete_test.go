@@ -0,0 +1,108 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for deleted category is hidden and can be restored
+func TestSoftDeleteAndRestoreCategory(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Create categories, then delete one category
+	gadget := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+	createCategoryWithKey(t, router, "Book", "RAHASIA")
+	id := strconv.Itoa(int(gadget["id"].(float64)))
+
+	request := httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/"+id, nil)
+	response, _ := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+
+	// (2) Deleted category is not found and not listed
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+id, nil)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 404, response.StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	_, responseBody := doRequest(router, request)
+	assert.Equal(t, []string{"Book"}, responseNames(responseBody))
+
+	// (3) Deleted category is listed with deleted time when requested
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?include_deleted=true", nil)
+	_, responseBody = doRequest(router, request)
+	assert.Equal(t, []string{"Gadget", "Book"}, responseNames(responseBody))
+	categories := responseBody["data"].([]interface{})
+	assert.NotNil(t, categories[0].(map[string]interface{})["deleted_at"])
+	assert.Nil(t, categories[1].(map[string]interface{})["deleted_at"])
+
+	// (4) Only delete scope can restore category
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/"+id+"/restore", nil)
+	response, _ = doRequestWithKey(router, request, "RAHASIA-BACA")
+	assert.Equal(t, 403, response.StatusCode)
+
+	// (5) Restored category can be found again
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/"+id+"/restore", nil)
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	restored := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, "Gadget", restored["name"])
+	assert.Nil(t, restored["deleted_at"])
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+id, nil)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+
+	// (6) Include deleted that not boolean is validation error
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories?include_deleted=maybe", nil)
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "include_deleted", responseBody["data"].([]interface{})[0].(map[string]interface{})["field"])
+}
+
+// Function test for purge category that deleted before retention window
+func TestPurgeDeletedCategory(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Create and delete category
+	gadget := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+	id := strconv.Itoa(int(gadget["id"].(float64)))
+	request := httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/"+id, nil)
+	doRequest(router, request)
+
+	// (2) Category deleted in default retention window is not purged
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/categories/purge", nil)
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, 0, int(responseBody["data"].(map[string]interface{})["purged"].(float64)))
+
+	// (3) Only admin can purge category
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/categories/purge?retention=0s", nil)
+	response, _ = doRequestWithKey(router, request, "RAHASIA-BACA")
+	assert.Equal(t, 403, response.StatusCode)
+
+	// (4) Category is purged without retention window and can not be restored
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/categories/purge?retention=0s", nil)
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, 1, int(responseBody["data"].(map[string]interface{})["purged"].(float64)))
+
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/"+id+"/restore", nil)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 404, response.StatusCode)
+
+	// (5) Retention that not duration or negative is validation error
+	for _, retention := range []string{"month", "-1h"} {
+		request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/categories/purge?retention="+retention, nil)
+		response, responseBody = doRequest(router, request)
+		assert.Equal(t, 400, response.StatusCode, retention)
+		assert.Equal(t, "retention", responseBody["data"].([]interface{})[0].(map[string]interface{})["field"])
+	}
+}
@@ -0,0 +1,209 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/app"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/model/web"
+	"github.com/jabutech/go-crud-restful-api/repository"
+	"github.com/jabutech/go-crud-restful-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for create category with parent, return id of category
+func createChildCategory(t *testing.T, router http.Handler, name string, parentId int) int {
+	body := `{"name": "` + name + `", "parent_id": ` + strconv.Itoa(parentId) + `}`
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(body))
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode, name)
+
+	return int(responseBody["data"].(map[string]interface{})["id"].(float64))
+}
+
+// Function for get the first field error in response
+func responseFieldError(responseBody map[string]interface{}) map[string]interface{} {
+	return responseBody["data"].([]interface{})[0].(map[string]interface{})
+}
+
+// Function test for find children, ancestors and tree of category
+func TestCategoryTree(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Create nested category until max depth
+	electronic := createChildCategory(t, router, "Electronic", 0)
+	phone := createChildCategory(t, router, "Phone", electronic)
+	android := createChildCategory(t, router, "Android", phone)
+	createChildCategory(t, router, "Book", 0)
+
+	// (2) Children only contain direct children
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+strconv.Itoa(electronic)+"/children", nil)
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, []string{"Phone"}, responseNames(responseBody))
+
+	// (3) Ancestors ordered from root category
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+strconv.Itoa(android)+"/ancestors", nil)
+	_, responseBody = doRequest(router, request)
+	assert.Equal(t, []string{"Electronic", "Phone"}, responseNames(responseBody))
+
+	// (4) Tree contain all category as nested children
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/tree", nil)
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, []string{"Electronic", "Book"}, responseNames(responseBody))
+	tree := responseBody["data"].([]interface{})[0].(map[string]interface{})
+	phoneTree := tree["children"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Phone", phoneTree["name"])
+	assert.Equal(t, float64(electronic), phoneTree["parent_id"])
+	assert.Equal(t, "Android", phoneTree["children"].([]interface{})[0].(map[string]interface{})["name"])
+}
+
+// Function test for validate parent of category
+func TestCategoryParentValidation(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	electronic := createChildCategory(t, router, "Electronic", 0)
+	phone := createChildCategory(t, router, "Phone", electronic)
+	android := createChildCategory(t, router, "Android", phone)
+
+	// (1) Category deeper than max depth is validation error
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "Pixel", "parent_id": `+strconv.Itoa(android)+`}`))
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "max_depth", responseFieldError(responseBody)["rule"])
+	assert.Equal(t, "category can not be deeper than 3 levels", responseFieldError(responseBody)["message"])
+
+	// (2) Parent that not available is validation error
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "Pixel", "parent_id": 404}`))
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "exists", responseFieldError(responseBody)["rule"])
+
+	// (3) Category can not be moved to its descendant
+	request = httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+strconv.Itoa(electronic), strings.NewReader(`{"name": "Electronic", "parent_id": `+strconv.Itoa(android)+`}`))
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "cycle", responseFieldError(responseBody)["rule"])
+
+	request = httptest.NewRequest(http.MethodPatch, "http://localhost:3000/api/categories/"+strconv.Itoa(phone), strings.NewReader(`{"parent_id": `+strconv.Itoa(phone)+`}`))
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "cycle", responseFieldError(responseBody)["rule"])
+
+	// (4) Moving category with its children is limited by max depth
+	book := createChildCategory(t, router, "Book", 0)
+	request = httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+strconv.Itoa(phone), strings.NewReader(`{"name": "Phone", "parent_id": `+strconv.Itoa(book)+`}`))
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+
+	novel := createChildCategory(t, router, "Novel", book)
+	request = httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+strconv.Itoa(phone), strings.NewReader(`{"name": "Phone", "parent_id": `+strconv.Itoa(novel)+`}`))
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "max_depth", responseFieldError(responseBody)["rule"])
+}
+
+// Function test for delete category that has children
+func TestDeleteCategoryWithChildren(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	electronic := createChildCategory(t, router, "Electronic", 0)
+	phone := createChildCategory(t, router, "Phone", electronic)
+	android := createChildCategory(t, router, "Android", phone)
+	deleteCategory := func(id int, children string) *http.Response {
+		request := httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/"+strconv.Itoa(id)+"?children="+children, nil)
+		response, _ := doRequest(router, request)
+		return response
+	}
+
+	// (1) Category that has children is rejected by default
+	assert.Equal(t, 409, deleteCategory(phone, "").StatusCode)
+	assert.Equal(t, 400, deleteCategory(phone, "orphan").StatusCode)
+
+	// (2) Children is moved to parent of deleted category
+	assert.Equal(t, 200, deleteCategory(phone, "reparent").StatusCode)
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+strconv.Itoa(android), nil)
+	_, responseBody := doRequest(router, request)
+	assert.Equal(t, float64(electronic), responseBody["data"].(map[string]interface{})["parent_id"])
+
+	// (3) All descendant is deleted with the category
+	tablet := createChildCategory(t, router, "Tablet", electronic)
+	createChildCategory(t, router, "iPad", tablet)
+	assert.Equal(t, 200, deleteCategory(electronic, "cascade").StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/tree", nil)
+	_, responseBody = doRequest(router, request)
+	assert.Empty(t, responseBody["data"])
+
+	// (4) Child can only be restored after its parent
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/"+strconv.Itoa(android)+"/restore", nil)
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 409, response.StatusCode)
+	assert.Equal(t, "parent category "+strconv.Itoa(electronic)+" is deleted, restore it first", responseBody["data"])
+
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/"+strconv.Itoa(electronic)+"/restore", nil)
+	doRequest(router, request)
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/"+strconv.Itoa(android)+"/restore", nil)
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+}
+
+// Repository that count query for find children of category
+type countingCategoryRepository struct {
+	repository.CategoryRepository
+	findAll         int
+	findDescendants int
+}
+
+func (repository *countingCategoryRepository) FindAll(ctx context.Context, tx repository.Tx, filter domain.CategoryFilter) ([]domain.Category, error) {
+	repository.findAll++
+	return repository.CategoryRepository.FindAll(ctx, tx, filter)
+}
+
+func (repository *countingCategoryRepository) FindDescendants(ctx context.Context, tx repository.Tx, categoryIds []int) ([]domain.Category, error) {
+	repository.findDescendants++
+	return repository.CategoryRepository.FindDescendants(ctx, tx, categoryIds)
+}
+
+// Function test for descendant of category is found with one query, not one query for each parent
+func TestCategoryDescendantsQuery(t *testing.T) {
+	db := repository.NewMemoryDB()
+	categoryRepository := &countingCategoryRepository{CategoryRepository: repository.NewCategoryRepositoryMemory(db)}
+	categoryService := service.NewCategoryService(categoryRepository, repository.NewUnitOfWork(db), app.NewValidator(), service.CategoryTreeConfig{MaxDepth: 5}, nil)
+	ctx := context.Background()
+
+	// (1) Create root category with three children, each child has two children
+	root, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: "Root"})
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		child, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: "Child " + strconv.Itoa(i), ParentId: root.Id})
+		assert.Nil(t, err)
+		for j := 0; j < 2; j++ {
+			_, err := categoryService.Create(ctx, web.CategoryCreateRequest{Name: "Grandchild " + strconv.Itoa(i) + strconv.Itoa(j), ParentId: child.Id})
+			assert.Nil(t, err)
+		}
+	}
+
+	// (2) Delete with cascade find all descendant with one query
+	categoryRepository.findAll, categoryRepository.findDescendants = 0, 0
+	assert.Nil(t, categoryService.Delete(ctx, web.CategoryDeleteRequest{Id: root.Id, Children: service.DeleteChildrenCascade}))
+	assert.Equal(t, 0, categoryRepository.findAll)
+	assert.Equal(t, 1, categoryRepository.findDescendants)
+
+	tree, err := categoryService.FindTree(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, tree)
+}
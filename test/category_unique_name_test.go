@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for name of category is unique case insensitive
+func TestCategoryUniqueName(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	gadget := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+	gadgetId := strconv.Itoa(int(gadget["id"].(float64)))
+	book := createCategoryWithKey(t, router, "Book", "RAHASIA")
+	bookId := strconv.Itoa(int(book["id"].(float64)))
+
+	// (1) Create category with used name is conflict, response contain id of the existing category
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "GADGET"}`))
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 409, response.StatusCode)
+	assert.Equal(t, "CONFLICT", responseBody["status"])
+	conflict := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, "category name GADGET is already used by category "+gadgetId, conflict["message"])
+	assert.Equal(t, gadget["id"], conflict["existing_id"])
+
+	// (2) Update category to name of other category is conflict
+	request = httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+bookId, strings.NewReader(`{"name": "gadget"}`))
+	request.Header.Set("Accept-Language", "id")
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 409, response.StatusCode)
+	assert.Equal(t, "nama kategori gadget sudah digunakan oleh kategori "+gadgetId, responseBody["data"].(map[string]interface{})["message"])
+
+	// (3) Update case of name of the same category is success
+	request = httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+gadgetId, strings.NewReader(`{"name": "GADGET"}`))
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 200, response.StatusCode)
+
+	// (4) Name of deleted category can be used again, but the deleted category can not be restored
+	request = httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/"+gadgetId, nil)
+	doRequest(router, request)
+	newGadget := createCategoryWithKey(t, router, "Gadget", "RAHASIA")
+
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/"+gadgetId+"/restore", nil)
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 409, response.StatusCode)
+	conflict = responseBody["data"].(map[string]interface{})
+	assert.Equal(t, "category name GADGET is already used by category "+strconv.Itoa(int(newGadget["id"].(float64))), conflict["message"])
+	assert.Equal(t, newGadget["id"], conflict["existing_id"])
+
+	// (5) Problem contain id of the existing category as extension member
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "gadget"}`))
+	request.Header.Set("Accept", "application/problem+json")
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 409, response.StatusCode)
+	assert.Equal(t, "category name gadget is already used by category "+strconv.Itoa(int(newGadget["id"].(float64))), responseBody["detail"])
+	assert.Equal(t, newGadget["id"], responseBody["existing_id"])
+
+	// (6) Failed item of bulk request contain id of the existing category
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/bulk?mode=best_effort", strings.NewReader(`[{"name": "Book"}, {"name": "Pen"}]`))
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 207, response.StatusCode)
+	items := responseBody["data"].(map[string]interface{})["items"].([]interface{})
+	assert.Equal(t, book["id"], items[0].(map[string]interface{})["existing_id"])
+	assert.Nil(t, items[1].(map[string]interface{})["existing_id"])
+}
+
+// Function test for name that checked by storage, e.g. category created by other transaction after name is checked
+func TestCategoryUniqueNameStorage(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	ctx := context.Background()
+	unitOfWork := repository.NewUnitOfWork(db)
+
+	// (1) Name of active category is unique case insensitive
+	var gadget domain.Category
+	err := unitOfWork.Do(ctx, func(tx repository.Tx) error {
+		var err error
+		gadget, err = db.CategoryRepository.Save(ctx, tx, domain.Category{Name: "Gadget"})
+		return err
+	})
+	assert.Nil(t, err)
+	err = unitOfWork.Do(ctx, func(tx repository.Tx) error {
+		_, err := db.CategoryRepository.Save(ctx, tx, domain.Category{Name: "GADGET"})
+		return err
+	})
+	assert.IsType(t, exception.ConflictError{}, err)
+	assert.Equal(t, "category name is already used by other category", err.Error())
+
+	// (2) Name of deleted category can be used again
+	err = unitOfWork.Do(ctx, func(tx repository.Tx) error {
+		gadget.DeletedAt = time.Now()
+		if err := db.CategoryRepository.Delete(ctx, tx, gadget); err != nil {
+			return err
+		}
+		_, err := db.CategoryRepository.Save(ctx, tx, domain.Category{Name: "gadget"})
+		return err
+	})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,195 @@
+package test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Function for send request with header Accept and content type, return the raw body
+func negotiateRequest(router http.Handler, method string, url string, accept string, contentType string, body []byte) (*http.Response, []byte) {
+	request := httptest.NewRequest(method, "http://localhost:3000"+url, bytes.NewReader(body))
+	request.Header.Add("X-API-Key", "RAHASIA")
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	responseBody, _ := io.ReadAll(response.Body)
+	return response, responseBody
+}
+
+// Struct for decode category response as XML
+type categoryXMLResponse struct {
+	XMLName xml.Name `xml:"response"`
+	Code    int      `xml:"code"`
+	Status  string   `xml:"status"`
+	Data    struct {
+		Id       int    `xml:"id"`
+		Name     string `xml:"name"`
+		ParentId *int   `xml:"parent_id"`
+	} `xml:"data"`
+}
+
+// Function test for write response with media type from header Accept
+func TestResponseMediaType(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	gadget := createChildCategory(t, router, "Gadget", 0)
+	phone := createChildCategory(t, router, "Phone", gadget)
+	url := "/api/categories/" + strconv.Itoa(phone)
+
+	// (1) XML use name of field in JSON, null is not written
+	response, body := negotiateRequest(router, http.MethodGet, url, "application/xml", "", nil)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "application/xml", response.Header.Get("Content-Type"))
+	assert.Equal(t, "Accept", response.Header.Get("Vary"))
+	categoryResponse := categoryXMLResponse{}
+	assert.Nil(t, xml.Unmarshal(body, &categoryResponse))
+	assert.Equal(t, 200, categoryResponse.Code)
+	assert.Equal(t, "Phone", categoryResponse.Data.Name)
+	assert.Equal(t, gadget, *categoryResponse.Data.ParentId)
+
+	// (2) MessagePack use name of field in JSON
+	response, body = negotiateRequest(router, http.MethodGet, url, "application/msgpack", "", nil)
+	assert.Equal(t, "application/msgpack", response.Header.Get("Content-Type"))
+	var messagePackResponse map[string]interface{}
+	assert.Nil(t, msgpack.Unmarshal(body, &messagePackResponse))
+	assert.Equal(t, "Phone", messagePackResponse["data"].(map[string]interface{})["name"])
+
+	// (3) List of category can be written as csv
+	response, body = negotiateRequest(router, http.MethodGet, "/api/categories", "text/csv", "", nil)
+	assert.Equal(t, "text/csv; charset=utf-8", response.Header.Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[2], strconv.Itoa(phone)+",Phone,"+strconv.Itoa(gadget)+","))
+
+	// (4) Media type with the highest quality is chosen, the next media type is used for response that not category
+	response, _ = negotiateRequest(router, http.MethodGet, url, "application/json;q=0.5, application/xml", "", nil)
+	assert.Equal(t, "application/xml", response.Header.Get("Content-Type"))
+	response, _ = negotiateRequest(router, http.MethodGet, "/api/categories/tree", "text/csv, application/json;q=0.5", "", nil)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+
+	// (5) Error response use the same media type
+	response, body = negotiateRequest(router, http.MethodGet, "/api/categories/404", "application/xml", "", nil)
+	assert.Equal(t, 404, response.StatusCode)
+	assert.Equal(t, "application/xml", response.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), "<status>NOT FOUND</status>")
+}
+
+// Function test for header Accept that no media type is supported
+func TestNotAcceptable(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	response, body := negotiateRequest(router, http.MethodGet, "/api/categories", "image/png", "", nil)
+	assert.Equal(t, 406, response.StatusCode)
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), "none of media type in header Accept is supported, use application/json, application/xml")
+
+	// Wildcard of type is accepted
+	response, _ = negotiateRequest(router, http.MethodGet, "/api/categories", "text/*", "", nil)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", response.Header.Get("Content-Type"))
+
+	// Response that can not be encoded with accepted media type is not acceptable, media type
+	// that can encode the response is written in message
+	for _, url := range []string{"/api/admin/keys", "/api/categories/tree"} {
+		response, body = negotiateRequest(router, http.MethodGet, url, "text/csv", "", nil)
+		assert.Equal(t, 406, response.StatusCode, url)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"), url)
+		assert.Contains(t, string(body), "none of media type in header Accept is supported, use application/json, application/xml, application/msgpack\"", url)
+	}
+}
+
+// Function test for request that change data with header Accept that can not write its response
+func TestNotAcceptableMutation(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	gadget := createChildCategory(t, router, "Gadget", 0)
+	url := "/api/categories/" + strconv.Itoa(gadget)
+
+	// (1) Request is rejected before processed, so category is not changed
+	for _, request := range []struct {
+		method string
+		url    string
+		body   string
+	}{
+		{http.MethodPost, "/api/categories", `{"name": "Phone"}`},
+		{http.MethodPut, url, `{"name": "Phone"}`},
+		{http.MethodDelete, url, ``},
+		{http.MethodPost, "/api/categories/bulk", `[{"name": "Phone"}]`},
+	} {
+		for _, accept := range []string{"text/csv", "application/x-ndjson"} {
+			response, body := negotiateRequest(router, request.method, request.url, accept, "application/json", []byte(request.body))
+			assert.Equal(t, 406, response.StatusCode, request.method+" "+request.url)
+			assert.Contains(t, string(body), "none of media type in header Accept is supported, use application/json, application/xml, application/msgpack\"")
+		}
+	}
+	assert.Equal(t, []string{"Gadget"}, allCategoryNames(router))
+
+	// (2) Category can not be written as csv, so the next accepted media type is used
+	response, _ := negotiateRequest(router, http.MethodGet, url, "text/csv, application/json;q=0.5", "", nil)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+}
+
+// Function test for read request body with content type XML and MessagePack
+func TestRequestMediaType(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	gadget := createChildCategory(t, router, "Gadget", 0)
+
+	// (1) XML use name of field in JSON, name of root element is not checked
+	body := []byte(`<?xml version="1.0"?><category><name>Phone</name><parent_id>` + strconv.Itoa(gadget) + `</parent_id></category>`)
+	response, responseBody := negotiateRequest(router, http.MethodPost, "/api/categories", "", "application/xml", body)
+	assert.Equal(t, 200, response.StatusCode, string(responseBody))
+	assert.Contains(t, string(responseBody), `"parent_id":`+strconv.Itoa(gadget))
+
+	// (2) Error of XML is the same as JSON
+	for body, message := range map[string]string{
+		`<category><name>Pen</name><parent_id>abc</parent_id></category>`: "field parent_id in request body must be a number",
+		`<category><name>Pen</name><color>red</color></category>`:         "field color in request body is not known",
+		`<category><name>Pen</name>`:                                      "request body is not valid XML",
+		``:                                                                "request body is required",
+	} {
+		response, responseBody = negotiateRequest(router, http.MethodPost, "/api/categories", "application/problem+json", "application/xml", []byte(body))
+		assert.Equal(t, 400, response.StatusCode, body)
+		assert.Contains(t, string(responseBody), message, body)
+	}
+
+	// (3) MessagePack use name of field in JSON
+	body, _ = msgpack.Marshal(map[string]interface{}{"name": "Book"})
+	response, responseBody = negotiateRequest(router, http.MethodPost, "/api/categories", "", "application/msgpack", body)
+	assert.Equal(t, 200, response.StatusCode, string(responseBody))
+	assert.Equal(t, []string{"Gadget", "Phone", "Book"}, allCategoryNames(router))
+
+	// (4) Unknown field and data after the value is not allowed
+	body, _ = msgpack.Marshal(map[string]interface{}{"name": "Pen", "color": "red"})
+	response, responseBody = negotiateRequest(router, http.MethodPost, "/api/categories", "application/problem+json", "application/msgpack", body)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Contains(t, string(responseBody), "field color in request body is not known")
+	body, _ = msgpack.Marshal(map[string]interface{}{"name": "Pen"})
+	response, responseBody = negotiateRequest(router, http.MethodPost, "/api/categories", "application/problem+json", "application/msgpack", append(body, 1))
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Contains(t, string(responseBody), "request body must only contain one MessagePack value")
+}
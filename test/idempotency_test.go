@@ -0,0 +1,143 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/middleware"
+	"github.com/jabutech/go-crud-restful-api/repository"
+	"github.com/jabutech/go-crud-restful-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for send request create category with header Idempotency-Key, return the raw body
+func createCategoryIdempotent(router http.Handler, key string, apiKey string, body string) (*http.Response, string) {
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(body))
+	request.Header.Add("X-API-Key", apiKey)
+	request.Header.Add("Idempotency-Key", key)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	responseBody, _ := io.ReadAll(response.Body)
+	return response, string(responseBody)
+}
+
+// Function test for retry create category with header Idempotency-Key
+func TestCreateCategoryIdempotent(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	truncateIdempotencyKey(db)
+	router := setupRouter(db)
+
+	// (1) Retried request get the same response, category is only created once
+	response, body := createCategoryIdempotent(router, "create-gadget", "RAHASIA", `{"name": "Gadget"}`)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "", response.Header.Get("Idempotent-Replayed"))
+	replayed, replayedBody := createCategoryIdempotent(router, "create-gadget", "RAHASIA", `{"name": "Gadget"}`)
+	assert.Equal(t, 200, replayed.StatusCode)
+	assert.Equal(t, "true", replayed.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, response.Header.Get("Content-Type"), replayed.Header.Get("Content-Type"))
+	assert.Equal(t, response.Header.Get("ETag"), replayed.Header.Get("ETag"))
+	assert.Equal(t, body, replayedBody)
+	assert.Equal(t, []string{"Gadget"}, allCategoryNames(router))
+
+	// (2) The same key for other request is rejected
+	response, body = createCategoryIdempotent(router, "create-gadget", "RAHASIA", `{"name": "Book"}`)
+	assert.Equal(t, 422, response.StatusCode)
+	assert.Contains(t, body, "header Idempotency-Key is already used for other request")
+
+	// (3) Key is only unique for the same caller
+	response, _ = createCategoryIdempotent(router, "create-gadget", "RAHASIA-LAIN", `{"name": "Gadget"}`)
+	assert.Equal(t, 409, response.StatusCode)
+	assert.Equal(t, "", response.Header.Get("Idempotent-Replayed"))
+
+	// (4) Client error is also replayed
+	response, _ = createCategoryIdempotent(router, "create-empty", "RAHASIA", `{"name": ""}`)
+	assert.Equal(t, 400, response.StatusCode)
+	replayed, _ = createCategoryIdempotent(router, "create-empty", "RAHASIA", `{"name": ""}`)
+	assert.Equal(t, 400, replayed.StatusCode)
+	assert.Equal(t, "true", replayed.Header.Get("Idempotent-Replayed"))
+
+	// (5) Key that too long is rejected
+	response, _ = createCategoryIdempotent(router, strings.Repeat("k", 256), "RAHASIA", `{"name": "Book"}`)
+	assert.Equal(t, 400, response.StatusCode)
+
+	// (6) The same key for other representation is other request
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "Gadget"}`))
+	request.Header.Add("Idempotency-Key", "create-gadget")
+	request.Header.Add("Accept", "application/xml")
+	response, _ = doRequest(router, request)
+	assert.Equal(t, 422, response.StatusCode)
+
+	// (7) Request body that too large is rejected before read to memory
+	response, _ = createCategoryIdempotent(router, "create-large", "RAHASIA", `{"name": "`+strings.Repeat("a", middleware.DefaultIdempotencyMaxBodySize)+`"}`)
+	assert.Equal(t, 413, response.StatusCode)
+	assert.Equal(t, []string{"Gadget"}, allCategoryNames(router))
+}
+
+// Function test for reserve key that still processed, released or expired
+func TestIdempotencyKeyReserve(t *testing.T) {
+	db := setupTestDB()
+	truncateIdempotencyKey(db)
+	ctx := context.Background()
+	idempotencyService := service.NewIdempotencyService(db.IdempotencyKeyRepository, repository.NewUnitOfWork(db), 2*time.Second, 500*time.Millisecond)
+
+	// (1) Key that still processed is not reserved again
+	idempotencyKey, reserved, err := idempotencyService.Reserve(ctx, "api_key:test", "key", "fingerprint")
+	assert.Nil(t, err)
+	assert.True(t, reserved)
+	saved, reserved, err := idempotencyService.Reserve(ctx, "api_key:test", "key", "fingerprint")
+	assert.Nil(t, err)
+	assert.False(t, reserved)
+	assert.False(t, saved.Completed())
+
+	// (2) Released key can be reserved again
+	assert.Nil(t, idempotencyService.Release(ctx, idempotencyKey))
+	_, reserved, _ = idempotencyService.Reserve(ctx, "api_key:test", "key", "fingerprint")
+	assert.True(t, reserved)
+
+	// (3) Key without response after lock timeout can be reserved again, e.g. server restarted
+	time.Sleep(600 * time.Millisecond)
+	idempotencyKey, reserved, _ = idempotencyService.Reserve(ctx, "api_key:test", "key", "other fingerprint")
+	assert.True(t, reserved)
+	assert.Equal(t, "other fingerprint", idempotencyKey.Fingerprint)
+
+	// (4) Completed key is not reserved after lock timeout, but expired key can be reserved again
+	idempotencyKey.StatusCode = 200
+	assert.Nil(t, idempotencyService.Complete(ctx, idempotencyKey))
+	time.Sleep(600 * time.Millisecond)
+	saved, reserved, _ = idempotencyService.Reserve(ctx, "api_key:test", "key", "other fingerprint")
+	assert.False(t, reserved)
+	assert.True(t, saved.Completed())
+	time.Sleep(2100 * time.Millisecond)
+	_, reserved, _ = idempotencyService.Reserve(ctx, "api_key:test", "key", "other fingerprint")
+	assert.True(t, reserved)
+}
+
+// Function test for key of request that handler panic, key is released so request can be retried
+func TestIdempotencyKeyHandlerPanic(t *testing.T) {
+	db := setupTestDB()
+	truncateIdempotencyKey(db)
+	idempotencyService := service.NewIdempotencyService(db.IdempotencyKeyRepository, repository.NewUnitOfWork(db), time.Hour, time.Hour)
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		panic("handler failed")
+	}), idempotencyService)
+
+	// (1) Panic is not handled by middleware
+	assert.Panics(t, func() {
+		createCategoryIdempotent(idempotencyMiddleware, "create-panic", "", `{"name": "Gadget"}`)
+	})
+
+	// (2) Retried request is processed again
+	idempotencyMiddleware.Handler = http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusCreated)
+	})
+	response, _ := createCategoryIdempotent(idempotencyMiddleware, "create-panic", "", `{"name": "Gadget"}`)
+	assert.Equal(t, 201, response.StatusCode)
+}
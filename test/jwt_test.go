@@ -0,0 +1,172 @@
+package test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jabutech/go-crud-restful-api/helper"
+	"github.com/jabutech/go-crud-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+const testJwtSecret = "test-secret"
+
+// Function for create JWT verifier used by router in test
+func testJwtVerifier() *middleware.JwtVerifier {
+	return &middleware.JwtVerifier{
+		Issuer:     "https://gateway.test",
+		Audience:   "category-api",
+		Leeway:     time.Minute,
+		HmacSecret: []byte(testJwtSecret),
+	}
+}
+
+// Function for create claims that valid for verifier in test
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "user-1",
+		"iss":   "https://gateway.test",
+		"aud":   []string{"other-api", "category-api"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "categories:read categories:write",
+	}
+}
+
+// Function for encode header and claims of JWT
+func jwtSigningInput(header map[string]interface{}, claims map[string]interface{}) string {
+	headerJson, _ := json.Marshal(header)
+	claimsJson, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString(headerJson) + "." + base64.RawURLEncoding.EncodeToString(claimsJson)
+}
+
+// Function for sign JWT with HS256
+func signHS256(claims map[string]interface{}, secret string) string {
+	input := jwtSigningInput(map[string]interface{}{"alg": "HS256", "typ": "JWT"}, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Function for sign JWT with RS256
+func signRS256(claims map[string]interface{}, key *rsa.PrivateKey, kid string) string {
+	input := jwtSigningInput(map[string]interface{}{"alg": "RS256", "typ": "JWT", "kid": kid}, claims)
+	hash := sha256.Sum256([]byte(input))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// Function test for request with bearer token
+func TestJwtBearerToken(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Set("Authorization", "Bearer "+signHS256(validClaims(), testJwtSecret))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Result().StatusCode)
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Set("Authorization", "Bearer "+signHS256(validClaims(), "wrong-secret"))
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	assert.Equal(t, 401, recorder.Result().StatusCode)
+	assert.Equal(t, `Bearer error="invalid_token"`, recorder.Result().Header.Get("WWW-Authenticate"))
+}
+
+// Function test for verified subject and claims saved in request context
+func TestJwtPrincipalInContext(t *testing.T) {
+	var name, method string
+	var claims map[string]interface{}
+	handler := middleware.NewAuthMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		principal, _ := helper.PrincipalFromContext(request.Context())
+		name, method, claims = principal.Name, principal.Method, principal.Claims
+	}), middleware.NewJwtAuthenticator(testJwtVerifier()))
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Set("Authorization", "bearer "+signHS256(validClaims(), testJwtSecret))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, "user-1", name)
+	assert.Equal(t, "jwt", method)
+	assert.Equal(t, "https://gateway.test", claims["iss"])
+}
+
+// Function test for verify registered claims
+func TestJwtClaims(t *testing.T) {
+	verifier := testJwtVerifier()
+
+	tests := map[string]func(claims map[string]interface{}){
+		"expired":        func(claims map[string]interface{}) { claims["exp"] = time.Now().Add(-2 * time.Minute).Unix() },
+		"without exp":    func(claims map[string]interface{}) { delete(claims, "exp") },
+		"not yet valid":  func(claims map[string]interface{}) { claims["nbf"] = time.Now().Add(2 * time.Minute).Unix() },
+		"wrong issuer":   func(claims map[string]interface{}) { claims["iss"] = "https://other.test" },
+		"wrong audience": func(claims map[string]interface{}) { claims["aud"] = "other-api" },
+		"without sub":    func(claims map[string]interface{}) { delete(claims, "sub") },
+	}
+	for name, change := range tests {
+		claims := validClaims()
+		change(claims)
+		_, err := verifier.Verify(signHS256(claims, testJwtSecret))
+		assert.NotNil(t, err, name)
+	}
+
+	// Expired token is still accepted within leeway
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+	_, err := verifier.Verify(signHS256(claims, testJwtSecret))
+	assert.Nil(t, err)
+
+	// Algorithm none is never accepted
+	token := jwtSigningInput(map[string]interface{}{"alg": "none"}, validClaims()) + "."
+	_, err = verifier.Verify(token)
+	assert.NotNil(t, err)
+}
+
+// Function test for verify RS256 token with key from JWKS file
+func TestJwtRS256WithJwks(t *testing.T) {
+	// (1) Create RSA key and write the public key to JWKS file
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwks := map[string]interface{}{
+		"keys": []map[string]interface{}{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	content, _ := json.Marshal(jwks)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	os.WriteFile(path, content, 0600)
+
+	// (2) Load key from file
+	keys, err := middleware.LoadJwks(path)
+	assert.Nil(t, err)
+	verifier := &middleware.JwtVerifier{RsaKeys: keys}
+
+	// (3) Token signed with the key is valid
+	claims, err := verifier.Verify(signRS256(validClaims(), key, "key-1"))
+	assert.Nil(t, err)
+	assert.Equal(t, "user-1", claims["sub"])
+
+	// (4) Token with unknown key id is not valid
+	_, err = verifier.Verify(signRS256(validClaims(), key, "key-2"))
+	assert.NotNil(t, err)
+
+	// (5) HS256 is not accepted when secret is not configured
+	_, err = verifier.Verify(signHS256(validClaims(), testJwtSecret))
+	assert.NotNil(t, err)
+}
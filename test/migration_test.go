@@ -0,0 +1,51 @@
+package test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/jabutech/go-crud-restful-api/migration"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for load migration embedded in binary
+func TestEmbeddedMigration(t *testing.T) {
+	migrations, err := migration.Embedded()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "create_category", migrations[0].Name)
+	for i := 1; i < len(migrations); i++ {
+		assert.Less(t, migrations[i-1].Version, migrations[i].Version)
+	}
+}
+
+// Function test for load migration from directory
+func TestLoadMigration(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_add_index.up.sql":   {Data: []byte("-- add index\nCREATE INDEX a ON t (a);\nCREATE INDEX b\n  ON t (b);\n")},
+		"sql/0002_add_index.down.sql": {Data: []byte("DROP INDEX a ON t;\nDROP INDEX b ON t;")},
+		"sql/0001_create_t.up.sql":    {Data: []byte("CREATE TABLE t (a INT, b INT);")},
+		"sql/0001_create_t.down.sql":  {Data: []byte("DROP TABLE t;")},
+	}
+
+	migrations, err := migration.Load(fsys, "sql")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(migrations))
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "add_index", migrations[1].Name)
+	assert.Equal(t, []string{"CREATE INDEX a ON t (a)", "CREATE INDEX b\n  ON t (b)"}, migrations[1].Up)
+	assert.Equal(t, []string{"DROP INDEX a ON t", "DROP INDEX b ON t"}, migrations[1].Down)
+}
+
+// Function test for load migration without down file
+func TestLoadMigrationIncomplete(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0001_create_t.up.sql": {Data: []byte("CREATE TABLE t (a INT);")},
+	}
+
+	_, err := migration.Load(fsys, "sql")
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for validation error with format problem+json
+func TestProblemValidationError(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": ""}`))
+	request.Header.Set("Accept", "application/json, application/problem+json")
+	response, responseBody := doRequest(router, request)
+
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "application/problem+json", response.Header.Get("Content-Type"))
+	assert.Equal(t, "about:blank", responseBody["type"])
+	assert.Equal(t, "Bad Request", responseBody["title"])
+	assert.Equal(t, 400, int(responseBody["status"].(float64)))
+	assert.Equal(t, "/api/categories", responseBody["instance"])
+	assert.NotEmpty(t, responseBody["detail"])
+
+	errors := responseBody["errors"].([]interface{})
+	assert.Equal(t, 1, len(errors))
+	assert.NotEmpty(t, errors[0].(map[string]interface{})["message"])
+}
+
+// Function test for not found and unauthorized error with format problem+json
+func TestProblemNotFoundAndUnauthorized(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/404?fields=name", nil)
+	request.Header.Set("Accept", "application/problem+json")
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 404, response.StatusCode)
+	assert.Equal(t, "Not Found", responseBody["title"])
+	assert.Equal(t, "category is not found", responseBody["detail"])
+	assert.Equal(t, "/api/categories/404?fields=name", responseBody["instance"])
+	assert.Nil(t, responseBody["errors"])
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Set("Accept", "application/problem+json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	response, responseBody = decodeResponse(recorder)
+	assert.Equal(t, 401, response.StatusCode)
+	assert.Equal(t, "application/problem+json", response.Header.Get("Content-Type"))
+	assert.Equal(t, "Unauthorized", responseBody["title"])
+}
+
+// Function test for web response is still the default format of error
+func TestProblemNotRequested(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	for _, accept := range []string{"", "application/json", "*/*", "application/problem+json;q=0"} {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/404", nil)
+		request.Header.Set("Accept", accept)
+		response, responseBody := doRequest(router, request)
+
+		assert.Equal(t, 404, response.StatusCode)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"), accept)
+		assert.Equal(t, "NOT FOUND", responseBody["status"], accept)
+	}
+}
+
+// Function test for internal error that detail is not sent to client
+func TestProblemInternalError(t *testing.T) {
+	for _, accept := range []string{"application/problem+json", "application/json"} {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+		request.Header.Set("Accept", accept)
+		recorder := httptest.NewRecorder()
+		exception.ErrorHandler(recorder, request, errors.New("dial tcp 10.0.0.1:3306: connection refused"))
+
+		response, responseBody := decodeResponse(recorder)
+		assert.Equal(t, 500, response.StatusCode)
+		if accept == "application/problem+json" {
+			assert.Equal(t, "internal server error", responseBody["detail"])
+		} else {
+			assert.Equal(t, "internal server error", responseBody["data"])
+		}
+	}
+}
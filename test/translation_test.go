@@ -0,0 +1,108 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for validation message translated to language in header Accept-Language
+func TestTranslateValidationError(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "`+strings.Repeat("a", 201)+`"}`))
+	request.Header.Set("Accept-Language", "id-ID,id;q=0.9,en;q=0.8")
+	response, responseBody := doRequest(router, request)
+
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "id", response.Header.Get("Content-Language"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "name", "rule": "max", "param": "200", "message": "name maksimal 200 karakter"},
+	}, responseBody["data"])
+}
+
+// Function test for error message and problem title translated
+func TestTranslateErrorMessage(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Use the supported language with highest quality
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/404", nil)
+	request.Header.Set("Accept-Language", "fr;q=1, en;q=0.1, id;q=0.5")
+	request.Header.Set("Accept", "application/problem+json")
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 404, response.StatusCode)
+	assert.Equal(t, "Tidak Ditemukan", responseBody["title"])
+	assert.Equal(t, "kategori tidak ditemukan", responseBody["detail"])
+
+	// (2) Message with changing part
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "Gadget"}`))
+	request.Header.Set("Accept-Language", "id")
+	response, responseBody = doRequestWithKey(router, request, "RAHASIA-BACA")
+	assert.Equal(t, 403, response.StatusCode)
+	assert.Equal(t, "scope categories:write diperlukan", responseBody["data"])
+}
+
+// Function test for message in fallback language when language is not supported
+func TestTranslateFallbackLanguage(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	for _, language := range []string{"", "fr-FR", "id;q=0", "*"} {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/404", nil)
+		request.Header.Set("Accept-Language", language)
+		response, responseBody := doRequest(router, request)
+
+		assert.Equal(t, "en", response.Header.Get("Content-Language"), language)
+		assert.Equal(t, "category is not found", responseBody["data"], language)
+	}
+}
+
+// Function test for message translated from its key, argument is not matched with other message
+func TestTranslateMessageKey(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/bulk", nil)
+	request.Header.Set("Accept-Language", "id")
+
+	// (1) Argument that look like other message is written as it is
+	err := exception.NewConflictError("category name {0} is already used by other item in request", "category is not found")
+	code, message, _ := exception.TranslateError(request, err)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "nama kategori category is not found sudah digunakan oleh item lain dalam request", message)
+
+	// (2) Message that not in catalog is written in english
+	code, message, _ = exception.TranslateError(request, exception.NewBadRequestError("{0} is too old", "token"))
+	assert.Equal(t, 400, code)
+	assert.Equal(t, "token is too old", message)
+}
+
+// Function test for catalog of each language contain the same key with the same placeholder
+func TestTranslateCatalog(t *testing.T) {
+	readCatalog := func(language string) map[string]string {
+		content, err := os.ReadFile("../exception/locales/" + language + ".json")
+		assert.Nil(t, err)
+		catalog := map[string]string{}
+		assert.Nil(t, json.Unmarshal(content, &catalog))
+		return catalog
+	}
+	placeholder := regexp.MustCompile(`\{[0-9]+\}`)
+
+	english, indonesian := readCatalog("en"), readCatalog("id")
+	assert.Equal(t, len(english), len(indonesian))
+	for key, text := range english {
+		assert.Equal(t, key, text)
+		translated, ok := indonesian[key]
+		assert.True(t, ok, key)
+		assert.ElementsMatch(t, placeholder.FindAllString(key, -1), placeholder.FindAllString(translated, -1), key)
+	}
+}
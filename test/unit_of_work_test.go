@@ -0,0 +1,69 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jabutech/go-crud-restful-api/model/domain"
+	"github.com/jabutech/go-crud-restful-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function for count all category in storage
+func countCategory(db *repository.MemoryDB, categoryRepository repository.CategoryRepository) int {
+	ctx := context.Background()
+	tx, _ := db.Begin(ctx)
+	defer tx.Rollback()
+
+	categories, _ := categoryRepository.FindAll(ctx, tx, domain.CategoryFilter{})
+	return len(categories)
+}
+
+// Function test for unit of work commit when success
+func TestUnitOfWorkCommit(t *testing.T) {
+	db := repository.NewMemoryDB()
+	categoryRepository := repository.NewCategoryRepositoryMemory(db)
+	unitOfWork := repository.NewUnitOfWork(db)
+	ctx := context.Background()
+
+	err := unitOfWork.Do(ctx, func(tx repository.Tx) error {
+		categoryRepository.Save(ctx, tx, domain.Category{Name: "Gadget"})
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, countCategory(db, categoryRepository))
+}
+
+// Function test for unit of work rollback when fn return error
+func TestUnitOfWorkRollbackOnError(t *testing.T) {
+	db := repository.NewMemoryDB()
+	categoryRepository := repository.NewCategoryRepositoryMemory(db)
+	unitOfWork := repository.NewUnitOfWork(db)
+	ctx := context.Background()
+
+	err := unitOfWork.Do(ctx, func(tx repository.Tx) error {
+		categoryRepository.Save(ctx, tx, domain.Category{Name: "Gadget"})
+		return errors.New("failed")
+	})
+
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, 0, countCategory(db, categoryRepository))
+}
+
+// Function test for unit of work rollback when fn panic
+func TestUnitOfWorkRollbackOnPanic(t *testing.T) {
+	db := repository.NewMemoryDB()
+	categoryRepository := repository.NewCategoryRepositoryMemory(db)
+	unitOfWork := repository.NewUnitOfWork(db)
+	ctx := context.Background()
+
+	assert.Panics(t, func() {
+		unitOfWork.Do(ctx, func(tx repository.Tx) error {
+			categoryRepository.Save(ctx, tx, domain.Category{Name: "Gadget"})
+			panic("failed")
+		})
+	})
+	assert.Equal(t, 0, countCategory(db, categoryRepository))
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function test for validation error show error of each field with json field name
+func TestValidationFieldErrors(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	// (1) Name is required
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": ""}`))
+	response, responseBody := doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "name", "rule": "required", "param": "", "message": "name is required"},
+	}, responseBody["data"])
+
+	// (2) Name is too long
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", strings.NewReader(`{"name": "`+strings.Repeat("a", 201)+`"}`))
+	response, responseBody = doRequest(router, request)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "name", "rule": "max", "param": "200", "message": "name must be at most 200 characters"},
+	}, responseBody["data"])
+}
+
+// Function test for validation error of query parameter
+func TestValidationQueryErrors(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	tests := map[string]string{
+		"/api/categories?size=ten":            "size",
+		"/api/categories?sort=created":        "sort",
+		"/api/categories?page=2&after=10":     "after",
+		"/api/categories?before=5&order=desc": "before",
+	}
+	for url, field := range tests {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:3000"+url, nil)
+		response, responseBody := doRequest(router, request)
+		assert.Equal(t, 400, response.StatusCode, url)
+
+		fieldErrors := responseBody["data"].([]interface{})
+		assert.Equal(t, field, fieldErrors[0].(map[string]interface{})["field"], url)
+	}
+}